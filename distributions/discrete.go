@@ -0,0 +1,132 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package distributions
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Poisson distribution with mean
+type Poisson struct {
+	MeanValue float64
+}
+
+// NewPoisson creates new Poisson distribution with mean
+func NewPoisson(mean float64) *Poisson {
+	return &Poisson{MeanValue: mean}
+}
+
+// Sample - draw random value. Knuth method is used for small mean, for big
+// mean the value is a sum of values with smaller means.
+func (d *Poisson) Sample(r *rand.Rand) float64 {
+	var sum float64
+	mean := d.MeanValue
+	for mean > 0 {
+		step := math.Min(mean, 30)
+		mean -= step
+		limit := math.Exp(-step)
+		p := r.Float64()
+		for p > limit {
+			sum++
+			p *= r.Float64()
+		}
+	}
+	return sum
+}
+
+// Mean - get mean of distribution
+func (d *Poisson) Mean() float64 {
+	return d.MeanValue
+}
+
+// Binomial distribution, number of successes in N trials with probability P
+type Binomial struct {
+	N int
+	P float64
+}
+
+// NewBinomial creates new Binomial distribution
+func NewBinomial(n int, p float64) *Binomial {
+	return &Binomial{N: n, P: p}
+}
+
+// Sample - draw random value
+func (d *Binomial) Sample(r *rand.Rand) float64 {
+	var sum float64
+	for i := 0; i < d.N; i++ {
+		if r.Float64() < d.P {
+			sum++
+		}
+	}
+	return sum
+}
+
+// Mean - get mean of distribution
+func (d *Binomial) Mean() float64 {
+	return float64(d.N) * d.P
+}
+
+// Empirical discrete distribution, it is set by values and their weights
+type Empirical struct {
+	Values     []float64
+	cumulative []float64
+}
+
+// NewEmpirical creates new Empirical distribution. values - values of
+// distribution; weights - weights of values, they are normalized, if weights
+// is nil all values have same weight, missing weights are zero. It panics if
+// weight is negative or not a number, or all weights are zero.
+func NewEmpirical(values, weights []float64) *Empirical {
+	d := &Empirical{Values: values, cumulative: make([]float64, len(values))}
+	var sum float64
+	for i := range values {
+		w := 1.0
+		if weights != nil {
+			if i < len(weights) {
+				w = weights[i]
+			} else {
+				w = 0
+			}
+		}
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			panic(fmt.Sprintf("empirical distribution: invalid weight %v of value %v", w, values[i]))
+		}
+		sum += w
+		d.cumulative[i] = sum
+	}
+	if len(values) > 0 && sum == 0 {
+		panic("empirical distribution: all weights are zero")
+	}
+	for i := range d.cumulative {
+		d.cumulative[i] /= sum
+	}
+	return d
+}
+
+// Sample - draw random value
+func (d *Empirical) Sample(r *rand.Rand) float64 {
+	if len(d.Values) == 0 {
+		return 0
+	}
+	u := r.Float64()
+	i := sort.Search(len(d.cumulative), func(i int) bool { return d.cumulative[i] > u })
+	if i >= len(d.Values) {
+		i = len(d.Values) - 1
+	}
+	return d.Values[i]
+}
+
+// Mean - get mean of distribution
+func (d *Empirical) Mean() float64 {
+	var mean, prev float64
+	for i, v := range d.Values {
+		mean += v * (d.cumulative[i] - prev)
+		prev = d.cumulative[i]
+	}
+	return mean
+}
@@ -0,0 +1,330 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+// Package distributions contains probability distributions for inter-arrival
+// times, service times and counts of simulation model
+package distributions
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Distribution implements interface of probability distribution
+type Distribution interface {
+	Sample(r *rand.Rand) float64 // Draw random value by selected generator
+	Mean() float64               // Get mean of distribution
+}
+
+// Int - draw random value and round it to the nearest integer
+func Int(d Distribution, r *rand.Rand) int {
+	return int(math.Round(d.Sample(r)))
+}
+
+// Constant distribution, it always returns same value
+type Constant struct {
+	Value float64
+}
+
+// NewConstant creates new Constant distribution
+func NewConstant(value float64) *Constant {
+	return &Constant{Value: value}
+}
+
+// Sample - draw random value
+func (d *Constant) Sample(r *rand.Rand) float64 {
+	return d.Value
+}
+
+// Mean - get mean of distribution
+func (d *Constant) Mean() float64 {
+	return d.Value
+}
+
+// Uniform distribution of integers between Min and Max inclusive, it is
+// Interval ± Modificator of blocks
+type Uniform struct {
+	Min int
+	Max int
+}
+
+// NewUniform creates new Uniform distribution of integers between min and max
+func NewUniform(min, max int) *Uniform {
+	return &Uniform{Min: min, Max: max}
+}
+
+// Sample - draw random value
+func (d *Uniform) Sample(r *rand.Rand) float64 {
+	if d.Max <= d.Min {
+		return float64(d.Min)
+	}
+	return float64(r.Intn(d.Max-d.Min+1) + d.Min)
+}
+
+// Mean - get mean of distribution
+func (d *Uniform) Mean() float64 {
+	return float64(d.Min+d.Max) / 2
+}
+
+// Exponential distribution with mean
+type Exponential struct {
+	MeanValue float64
+}
+
+// NewExponential creates new Exponential distribution with mean
+func NewExponential(mean float64) *Exponential {
+	return &Exponential{MeanValue: mean}
+}
+
+// Sample - draw random value
+func (d *Exponential) Sample(r *rand.Rand) float64 {
+	return r.ExpFloat64() * d.MeanValue
+}
+
+// Mean - get mean of distribution
+func (d *Exponential) Mean() float64 {
+	return d.MeanValue
+}
+
+// Normal distribution with mean and standard deviation
+type Normal struct {
+	MeanValue float64
+	StdDev    float64
+}
+
+// NewNormal creates new Normal distribution with mean and standard deviation
+func NewNormal(mean, stddev float64) *Normal {
+	return &Normal{MeanValue: mean, StdDev: stddev}
+}
+
+// Sample - draw random value
+func (d *Normal) Sample(r *rand.Rand) float64 {
+	return r.NormFloat64()*d.StdDev + d.MeanValue
+}
+
+// Mean - get mean of distribution
+func (d *Normal) Mean() float64 {
+	return d.MeanValue
+}
+
+// TruncatedNormal is Normal distribution limited by Min and Max, values are
+// drawn by inverse CDF, so range far in the tail does not slow down sampling
+type TruncatedNormal struct {
+	MeanValue float64
+	StdDev    float64
+	Min       float64
+	Max       float64
+}
+
+// NewTruncatedNormal creates new TruncatedNormal distribution with mean and
+// standard deviation of Normal distribution, limited by min and max
+func NewTruncatedNormal(mean, stddev, min, max float64) *TruncatedNormal {
+	return &TruncatedNormal{MeanValue: mean, StdDev: stddev, Min: min, Max: max}
+}
+
+// Sample - draw random value
+func (d *TruncatedNormal) Sample(r *rand.Rand) float64 {
+	if d.StdDev <= 0 || d.Max <= d.Min {
+		return math.Max(d.Min, math.Min(d.Max, d.MeanValue))
+	}
+	a := (d.Min - d.MeanValue) / d.StdDev
+	b := (d.Max - d.MeanValue) / d.StdDev
+	// Range in the upper tail is mirrored to the lower tail, where CDF is
+	// precise
+	mirror := a > 0
+	if mirror {
+		a, b = -b, -a
+	}
+	pa, pb := normalCDF(a), normalCDF(b)
+	x := b
+	if pb > pa {
+		x = math.Max(a, math.Min(b, normalQuantile(pa+r.Float64()*(pb-pa))))
+	}
+	if mirror {
+		x = -x
+	}
+	return x*d.StdDev + d.MeanValue
+}
+
+// normalCDF - CDF of standard normal distribution
+func normalCDF(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+// normalQuantile - inverse CDF of standard normal distribution, rational
+// approximation by P. J. Acklam refined by one step of Halley's method
+func normalQuantile(p float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	a := [...]float64{-3.969683028665376e+01, 2.209460984245205e+02, -2.759285104469687e+02,
+		1.383577518672690e+02, -3.066479806614716e+01, 2.506628277459239e+00}
+	b := [...]float64{-5.447609879822406e+01, 1.615858368580409e+02, -1.556989798598866e+02,
+		6.680131188771972e+01, -1.328068155288572e+01}
+	c := [...]float64{-7.784894002430293e-03, -3.223964580411365e-01, -2.400758277161838e+00,
+		-2.549732539343734e+00, 4.374664141464968e+00, 2.938163982698783e+00}
+	e := [...]float64{7.784695709041462e-03, 3.224671290700398e-01, 2.445134137142996e+00,
+		3.754408661907416e+00}
+	const low = 0.02425
+	var x float64
+	switch {
+	case p < low:
+		q := math.Sqrt(-2 * math.Log(p))
+		x = (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((e[0]*q+e[1])*q+e[2])*q+e[3])*q + 1)
+	case p > 1-low:
+		q := math.Sqrt(-2 * math.Log1p(-p))
+		x = -(((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((e[0]*q+e[1])*q+e[2])*q+e[3])*q + 1)
+	default:
+		q := p - 0.5
+		r := q * q
+		x = (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q /
+			(((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+	}
+	u := (normalCDF(x) - p) * math.Sqrt(2*math.Pi) * math.Exp(x*x/2)
+	return x - u/(1+x*u/2)
+}
+
+// Mean - get mean of distribution
+func (d *TruncatedNormal) Mean() float64 {
+	if d.StdDev <= 0 || d.Max <= d.Min {
+		return math.Max(d.Min, math.Min(d.Max, d.MeanValue))
+	}
+	alpha := (d.Min - d.MeanValue) / d.StdDev
+	beta := (d.Max - d.MeanValue) / d.StdDev
+	pdf := func(x float64) float64 { return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi) }
+	cdf := func(x float64) float64 { return (1 + math.Erf(x/math.Sqrt2)) / 2 }
+	return d.MeanValue + d.StdDev*(pdf(alpha)-pdf(beta))/(cdf(beta)-cdf(alpha))
+}
+
+// LogNormal distribution, logarithm of value has Normal distribution with Mu
+// and Sigma
+type LogNormal struct {
+	Mu    float64
+	Sigma float64
+}
+
+// NewLogNormal creates new LogNormal distribution, mu and sigma are mean and
+// standard deviation of logarithm of value
+func NewLogNormal(mu, sigma float64) *LogNormal {
+	return &LogNormal{Mu: mu, Sigma: sigma}
+}
+
+// Sample - draw random value
+func (d *LogNormal) Sample(r *rand.Rand) float64 {
+	return math.Exp(r.NormFloat64()*d.Sigma + d.Mu)
+}
+
+// Mean - get mean of distribution
+func (d *LogNormal) Mean() float64 {
+	return math.Exp(d.Mu + d.Sigma*d.Sigma/2)
+}
+
+// Triangular distribution between Min and Max with Mode
+type Triangular struct {
+	Min  float64
+	Mode float64
+	Max  float64
+}
+
+// NewTriangular creates new Triangular distribution
+func NewTriangular(min, mode, max float64) *Triangular {
+	return &Triangular{Min: min, Mode: mode, Max: max}
+}
+
+// Sample - draw random value
+func (d *Triangular) Sample(r *rand.Rand) float64 {
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	u := r.Float64()
+	f := (d.Mode - d.Min) / (d.Max - d.Min)
+	if u < f {
+		return d.Min + math.Sqrt(u*(d.Max-d.Min)*(d.Mode-d.Min))
+	}
+	return d.Max - math.Sqrt((1-u)*(d.Max-d.Min)*(d.Max-d.Mode))
+}
+
+// Mean - get mean of distribution
+func (d *Triangular) Mean() float64 {
+	return (d.Min + d.Mode + d.Max) / 3
+}
+
+// Gamma distribution with Shape and Scale
+type Gamma struct {
+	Shape float64
+	Scale float64
+}
+
+// NewGamma creates new Gamma distribution with shape and scale
+func NewGamma(shape, scale float64) *Gamma {
+	return &Gamma{Shape: shape, Scale: scale}
+}
+
+// NewErlang creates new Erlang distribution, sum of k exponential phases with
+// total mean
+func NewErlang(k int, mean float64) *Gamma {
+	if k < 1 {
+		k = 1
+	}
+	return &Gamma{Shape: float64(k), Scale: mean / float64(k)}
+}
+
+// Sample - draw random value, Marsaglia and Tsang method
+func (d *Gamma) Sample(r *rand.Rand) float64 {
+	if d.Shape <= 0 {
+		return 0
+	}
+	shape := d.Shape
+	boost := 1.0
+	if shape < 1 {
+		// Gamma(a) = Gamma(a+1) * U^(1/a)
+		boost = math.Pow(r.Float64(), 1/shape)
+		shape++
+	}
+	dd := shape - 1.0/3
+	c := 1 / math.Sqrt(9*dd)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := r.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < x*x/2+dd*(1-v+math.Log(v)) {
+			return dd * v * d.Scale * boost
+		}
+	}
+}
+
+// Mean - get mean of distribution
+func (d *Gamma) Mean() float64 {
+	return d.Shape * d.Scale
+}
+
+// Weibull distribution with Shape and Scale
+type Weibull struct {
+	Shape float64
+	Scale float64
+}
+
+// NewWeibull creates new Weibull distribution with shape and scale
+func NewWeibull(shape, scale float64) *Weibull {
+	return &Weibull{Shape: shape, Scale: scale}
+}
+
+// Sample - draw random value
+func (d *Weibull) Sample(r *rand.Rand) float64 {
+	return d.Scale * math.Pow(r.ExpFloat64(), 1/d.Shape)
+}
+
+// Mean - get mean of distribution
+func (d *Weibull) Mean() float64 {
+	return d.Scale * math.Gamma(1+1/d.Shape)
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package distributions
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDistribution_Mean(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Distribution
+	}{
+		{"Constant", NewConstant(5)},
+		{"Uniform", NewUniform(2, 8)},
+		{"Exponential", NewExponential(10)},
+		{"Normal", NewNormal(10, 2)},
+		{"TruncatedNormal", NewTruncatedNormal(10, 5, 8, 30)},
+		{"LogNormal", NewLogNormal(1, 0.5)},
+		{"Triangular", NewTriangular(2, 3, 10)},
+		{"Erlang", NewErlang(3, 9)},
+		{"Gamma", NewGamma(0.5, 4)},
+		{"Weibull", NewWeibull(1.5, 10)},
+		{"Poisson", NewPoisson(45)},
+		{"Binomial", NewBinomial(10, 0.3)},
+		{"Empirical", NewEmpirical([]float64{1, 2, 5}, []float64{1, 2, 1})},
+	}
+	r := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		var sum float64
+		n := 20000
+		for i := 0; i < n; i++ {
+			sum += tt.d.Sample(r)
+		}
+		mean := sum / float64(n)
+		if math.Abs(mean-tt.d.Mean()) > 0.03*tt.d.Mean() {
+			t.Error(tt.name, "sample mean, expected", tt.d.Mean(), "got", mean)
+		}
+	}
+}
+
+func TestInt(t *testing.T) {
+	if v := Int(NewConstant(2.5), nil); v != 3 {
+		t.Error("Rounded value, expected", 3, "got", v)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		if v := Int(NewUniform(-2, 2), r); v < -2 || v > 2 {
+			t.Fatal("Uniform value, expected between", -2, 2, "got", v)
+		}
+	}
+}
+
+func TestTruncatedNormal_Tail(t *testing.T) {
+	d := NewTruncatedNormal(0, 1, 10, 11)
+	r := rand.New(rand.NewSource(1))
+	var sum float64
+	n := 1000
+	for i := 0; i < n; i++ {
+		v := d.Sample(r)
+		if v < 10 || v > 11 {
+			t.Fatal("Value, expected between", 10, 11, "got", v)
+		}
+		sum += v
+	}
+	if mean := sum / float64(n); math.Abs(mean-10.1) > 0.02 {
+		t.Error("Sample mean, expected about", 10.1, "got", mean)
+	}
+	d = NewTruncatedNormal(0, 1, -12, -10)
+	if v := d.Sample(r); v < -12 || v > -10 {
+		t.Error("Value, expected between", -12, -10, "got", v)
+	}
+}
+
+func TestNormalQuantile(t *testing.T) {
+	for _, x := range []float64{-30, -20, -5, -1, 0, 0.5, 2} {
+		if v := normalQuantile(normalCDF(x)); math.Abs(v-x) > 1e-9*math.Max(1, math.Abs(x)) {
+			t.Error("Quantile of CDF, expected", x, "got", v)
+		}
+	}
+}
+
+func TestNewEmpirical_InvalidWeights(t *testing.T) {
+	tests := [][]float64{{0, 0}, {1, -1}, {math.NaN(), 1}}
+	for _, weights := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Weights", weights, "expected panic")
+				}
+			}()
+			NewEmpirical([]float64{1, 2}, weights)
+		}()
+	}
+}
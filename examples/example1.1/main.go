@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -10,27 +11,10 @@ import (
 	"github.com/soldatov-s/go-gpss/objects"
 )
 
-var (
-	exit chan struct{}
-)
-
-func doneHandler(p *objects.Pipeline) {
-	p.Report()
-	close(exit)
-}
-
-func signalLoop() {
-	closeSignal := make(chan os.Signal)
-	signal.Notify(closeSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-closeSignal
-		close(exit)
-	}()
-}
-
 func main() {
-	// Init exit chan
-	exit = make(chan struct{})
+	// Cancel simulation by signal
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	// Build pipeline
 	// Generator -> Queue -> Facility -> Hole
@@ -40,9 +24,9 @@ func main() {
 		AddObject(objects.NewFacility("Master", 16, 4)).
 		AddObject(objects.NewHole("Out"))
 	// Start simulation
-	p.Start(480)
-
-	<-p.Done
+	if _, err := p.Run(ctx, 480); err != nil {
+		fmt.Println("Simulation error:", err)
+	}
 	p.Report()
 
 	// Exit
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -10,31 +11,14 @@ import (
 	"github.com/soldatov-s/go-gpss/objects"
 )
 
-var (
-	exit chan struct{}
-)
-
-func doneHandler(p *objects.Pipeline) {
-	p.Report()
-	close(exit)
-}
-
-func signalLoop() {
-	closeSignal := make(chan os.Signal)
-	signal.Notify(closeSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-closeSignal
-		close(exit)
-	}()
-}
-
 func main() {
-	// Init exit chan
-	exit = make(chan struct{})
+	// Cancel simulation by signal
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	// Build pipeline
 	// Generator -> Queue -> ...
-	p := objects.NewPipeline("Barbershop", doneHandler).
+	p := objects.NewPipeline("Barbershop").
 		AddObject(objects.NewGenerator("Clients", 18, 6, 0, 0, nil)).
 		AddObject(objects.NewQueue("Chairs"))
 
@@ -49,12 +33,11 @@ func main() {
 		AddObject(fOUT).
 		AddObject(objects.NewHole("Out"))
 	// Start simulation
-	p.Start(480)
-
-	// Signal handler
-	signalLoop()
+	if _, err := p.Run(ctx, 480); err != nil {
+		fmt.Println("Simulation error:", err)
+	}
+	p.Report()
 
 	// Exit
-	<-exit
 	fmt.Println("Exit program")
 }
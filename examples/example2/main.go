@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -10,34 +11,17 @@ import (
 	"github.com/soldatov-s/go-gpss/objects"
 )
 
-var (
-	exit chan struct{}
-)
-
-func doneHandler(p *objects.Pipeline) {
-	p.Report()
-	close(exit)
-}
-
-func signalLoop() {
-	closeSignal := make(chan os.Signal)
-	signal.Notify(closeSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-closeSignal
-		close(exit)
-	}()
-}
-
 func main() {
-	// Init exit chan
-	exit = make(chan struct{})
+	// Cancel simulation by signal
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	// Build pipeline
 	// Generator -> Advance1 -> Advance2 -> Queue -> Facility1 OR Facility2 -> Advance3
 	//           ^                                                                 |
 	//           |                                                                 |
 	//           -------------------------------------------------------------------
-	p := objects.NewPipeline("Water Closet Simulation", doneHandler).
+	p := objects.NewPipeline("Water Closet Simulation").
 		AddObject(objects.NewGenerator("Office", 0, 0, 0, 10, nil)).
 		AddObject(objects.NewAdvance("Wanted to use the toilet", 90, 60)).
 		AddObject(objects.NewAdvance("Path to WC", 5, 3)).
@@ -47,12 +31,11 @@ func main() {
 		Loop("Wanted to use the toilet")
 
 	// Start simulation
-	p.Start(540)
-
-	// Signal handler
-	signalLoop()
+	if _, err := p.Run(ctx, 540); err != nil {
+		fmt.Println("Simulation error:", err)
+	}
+	p.Report()
 
 	// Exit
-	<-exit
 	fmt.Println("Exit program")
 }
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -10,33 +11,16 @@ import (
 	"github.com/soldatov-s/go-gpss/objects"
 )
 
-var (
-	exit chan struct{}
-)
-
-func doneHandler(p *objects.Pipeline) {
-	p.Report()
-	close(exit)
-}
-
-func signalLoop() {
-	closeSignal := make(chan os.Signal)
-	signal.Notify(closeSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-closeSignal
-		close(exit)
-	}()
-}
-
 func main() {
-	// Init exit chan
-	exit = make(chan struct{})
+	// Cancel simulation by signal
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
 	// Build pipeline
 	// Generator -> Queue1 -> Facility1 -> Split ---> ...
 	//                                            |
 	//                                            --> ...
-	p := objects.NewPipeline("Cafe Simulation", doneHandler).
+	p := objects.NewPipeline("Cafe Simulation").
 		AddObject(objects.NewGenerator("Visitors", 18, 6, 0, 0, nil)).
 		AddObject(objects.NewQueue("Visitors queue")).
 		AddObject(objects.NewFacility("Order Acceptance", 5, 3)).
@@ -67,12 +51,11 @@ func main() {
 	aggregate.LinkObject(objects.NewHole("Out"))
 
 	// Start simulation
-	p.Start(480)
-
-	// Signal handler
-	signalLoop()
+	if _, err := p.Run(ctx, 480); err != nil {
+		fmt.Println("Simulation error:", err)
+	}
+	p.Report()
 
 	// Exit
-	<-exit
 	fmt.Println("Exit program")
 }
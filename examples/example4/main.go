@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -12,37 +13,18 @@ import (
 	"github.com/soldatov-s/go-gpss/objects"
 )
 
-var (
-	exit chan struct{}
-)
-
-func doneHandler(p *objects.Pipeline) {
-	p.Report()
-	close(exit)
-}
-
-func signalLoop() {
-	closeSignal := make(chan os.Signal)
-	signal.Notify(closeSignal, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-closeSignal
-		close(exit)
-	}()
-}
-
 func main() {
-	// Init exit chan
-	exit = make(chan struct{})
+	// Cancel simulation by signal
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-	restaurant := objects.NewPipeline("Restaurant  Simulation", doneHandler)
-	// 1. Create the Generator and Queue of Visitors, create a Hole
+	restaurant := objects.NewPipeline("Restaurant  Simulation")
+	// 1. Create the Generator of Visitors, create a Hole
 	visitorsG := objects.NewGenerator("Visitors", 10, 5, 0, 0, nil)
 	out := objects.NewHole("Out")
-	visitorsQ := objects.NewQueue("Visitors queue")
-	// 2. Create the Check for checking size the Queue of Visitors
+	// 2. Create the Check for checking number of Visitors waiting for table
 	checkQueueHndl := func(obj *objects.Check, transact *objects.Transaction) bool {
-		queue := obj.Pipe.GetObjByName("Visitors queue")
-		return queue.(objects.IQueue).GetLength() < 6
+		return obj.Pipe.MustAttr("Q", "Wait for empty table") < 6
 	}
 	checkQueue := objects.NewCheck("Check size of Visitors queue", checkQueueHndl, out)
 	// 3. Create are Hostess
@@ -56,18 +38,17 @@ func main() {
 		ID := strconv.Itoa(i + 1)
 		tablesIN[i], tablesOUT[i] = objects.NewBifacility("Table " + ID)
 	}
-	// 5. Check that we have empty table
-	CheckEmptyTableHndl := func(obj *objects.Check, transact *objects.Transaction) bool {
+	// 5. Visitors wait until we have empty table
+	CheckEmptyTableHndl := func(obj *objects.Gate, transact *objects.Transaction) bool {
 		for i := 0; i < cntTables; i++ {
 			ID := strconv.Itoa(i + 1)
-			table := obj.Pipe.GetObjByName("Table " + ID).(objects.IFacility)
-			if table.IsEmpty() {
+			if obj.Pipe.MustAttr("F", "Table "+ID) == 0 {
 				return true
 			}
 		}
 		return false
 	}
-	checkEmptyTable := objects.NewCheck("Check empty table", CheckEmptyTableHndl, nil)
+	waitEmptyTable := objects.NewGate("Wait for empty table", CheckEmptyTableHndl)
 	// 6. Create the Queues and Facilities for waiters
 	cntWaiters := 8
 	waitersQueue := make([]objects.IBaseObj, cntWaiters)
@@ -106,7 +87,7 @@ func main() {
 		return func(obj *objects.Check, transact *objects.Transaction) bool {
 			for i := 0; i < 3; i++ {
 				ID := strconv.Itoa(i + id_table)
-				if transact.GetParameter("Facility").(string) == "Table "+ID {
+				if objects.GetOr(transact, "Facility", "") == "Table "+ID {
 					return true
 				}
 			}
@@ -128,8 +109,7 @@ func main() {
 	// 15. Append objects to a pipeline
 	restaurant.AddObject(visitorsG).
 		AddObject(checkQueue).
-		AddObject(visitorsQ).
-		AddObject(checkEmptyTable).
+		AddObject(waitEmptyTable).
 		AddObject(hostes1F, hostes2F)
 	hostes1F.LinkObject(tablesIN...)
 	hostes2F.LinkObject(tablesIN...)
@@ -167,12 +147,12 @@ func main() {
 	restaurant.AppendMultiple(tablesOUT, out)
 	restaurant.Append(out)
 
-	restaurant.Start(480)
-
-	// Signal handler
-	signalLoop()
+	// Start simulation
+	if _, err := restaurant.Run(ctx, 480); err != nil {
+		fmt.Println("Simulation error:", err)
+	}
+	restaurant.Report()
 
 	// Exit
-	<-exit
 	fmt.Println("Exit program")
 }
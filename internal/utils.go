@@ -6,17 +6,66 @@ package utils
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
+// random is a default generator, seeded by time once
+var random = NewRandom(time.Now().UnixNano())
+
+// lockedSource is a thread safe rand.Source64
+type lockedSource struct {
+	src rand.Source64
+	mu  sync.Mutex
+}
+
+// Int63 is part of rand.Source.
+func (s *lockedSource) Int63() int64 {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	return s.src.Int63()
+}
+
+// Uint64 is part of rand.Source64.
+func (s *lockedSource) Uint64() uint64 {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	return s.src.Uint64()
+}
+
+// Seed is part of rand.Source.
+func (s *lockedSource) Seed(seed int64) {
+	defer s.mu.Unlock()
+	s.mu.Lock()
+	s.src.Seed(seed)
+}
+
+// NewRandom - create new thread safe generator of random numbers with seed
+func NewRandom(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+// GetDefaultRandom - get default generator of random numbers
+func GetDefaultRandom() *rand.Rand {
+	return random
+}
+
 // GetRandom - generate random between min and max
 func GetRandom(min, max int) int {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return r.Intn(max-min+1) + min
+	return GetRandomFrom(random, min, max)
 }
 
 // GetRandomBool - Get random bool
 func GetRandomBool() bool {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return GetRandomBoolFrom(random)
+}
+
+// GetRandomFrom - generate random between min and max by selected generator
+func GetRandomFrom(r *rand.Rand, min, max int) int {
+	return r.Intn(max-min+1) + min
+}
+
+// GetRandomBoolFrom - get random bool by selected generator
+func GetRandomBoolFrom(r *rand.Rand) bool {
 	return r.Float32() < 0.5
 }
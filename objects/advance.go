@@ -6,8 +6,10 @@ package objects
 
 import (
 	"fmt"
+	"math"
 	"sync"
 
+	"github.com/soldatov-s/go-gpss/distributions"
 	utils "github.com/soldatov-s/go-gpss/internal"
 )
 
@@ -16,14 +18,31 @@ type IAdvance interface {
 	GenerateAdvance() int
 }
 
+// HandleAdvanceFunc is a function signature for generate advance of transact
+type HandleAdvanceFunc func(obj *Advance, transact *Transaction) int
+
+// AdvanceTime - default function for generate advance of transact, it is
+// Interval ± Modificator or value of Distribution. Function of pipeline used
+// as Distribution is evaluated for transact.
+func AdvanceTime(obj *Advance, transact *Transaction) int {
+	if f, ok := obj.Distribution.(*Function); ok {
+		return int(math.Round(f.Evaluate(transact)))
+	}
+	return obj.GenerateAdvance()
+}
+
 // Advance block delays the progress of a Transaction for a specified amount
 // of simulated time
 type Advance struct {
 	BaseObj
-	Interval    int     // The mean time increment
-	Modificator int     // The time half-range
-	sumAdvance  float64 // Totalize advance for all transacts
-	sumTransact float64 // Counter of transacts
+	Interval      int                        // The mean time increment
+	Modificator   int                        // The time half-range
+	Distribution  distributions.Distribution // Distribution of time increment, used instead of Interval and Modificator if set
+	Stream        int                        // Number of random stream, RN1 by default
+	HandleAdvance HandleAdvanceFunc          // Function for generate advance of transact
+	sumAdvance    float64                    // Totalize advance for all transacts
+	sumTransact   float64                    // Counter of transacts
+	events        map[int]*Event             // Scheduled end of delay for each transact
 }
 
 // NewAdvance creates new Advance.
@@ -34,63 +53,137 @@ func NewAdvance(name string, interval, modificator int) *Advance {
 	obj.BaseObj.Init(name)
 	obj.Interval = interval
 	obj.Modificator = modificator
+	obj.HandleAdvance = AdvanceTime
+	obj.events = make(map[int]*Event)
 	return obj
 }
 
 // GenerateAdvance generate advance
 func (obj *Advance) GenerateAdvance() int {
+	if obj.Distribution != nil {
+		return distributions.Int(obj.Distribution, obj.Pipe.RN(obj.Stream))
+	}
 	advance := obj.Interval
 	if obj.Modificator > 0 {
-		advance += utils.GetRandom(-obj.Modificator, obj.Modificator)
+		advance += utils.GetRandomFrom(obj.Pipe.RN(obj.Stream), -obj.Modificator, obj.Modificator)
 	}
 	return advance
 }
 
-// HandleTransact handle transact
-func (obj *Advance) HandleTransact(transact *Transaction) {
-	transact.DecTiсks()
+// HandleTransact handle transact, transact leaves advance if the delay is over.
+// Returns true if transact leaved advance.
+func (obj *Advance) HandleTransact(transact *Transaction) bool {
 	transact.PrintInfo()
 	if transact.IsTheEnd() {
+		e := obj.events[transact.GetID()]
 		for _, v := range obj.GetDst() {
 			if v.AppendTransact(transact) {
-				obj.tb.Remove(transact)
-				break
+				if obj.events[transact.GetID()] == e {
+					// Transact did not re-enter advance through destination
+					obj.tb.Remove(transact)
+					delete(obj.events, transact.GetID())
+				}
+				return true
 			}
 		}
 	}
+	return false
 }
 
-// HandleTransacts handle transacts in goroutine
-func (obj *Advance) HandleTransacts(wg *sync.WaitGroup) {
-	if obj.Interval == 0 ||
-		obj.tb.Len() == 0 {
-		wg.Done()
+// HandleEvent handle the end of delay of transact
+func (obj *Advance) HandleEvent(e *Event) {
+	if obj.events[e.Transact.GetID()] != e {
+		// Transact already left advance
 		return
 	}
-	go func() {
-		defer wg.Done()
-		transacts := obj.tb.Items()
-		for _, tr := range transacts {
-			obj.HandleTransact(tr.transact)
+	e.Transact.ResetTicks()
+	obj.HandleTransact(e.Transact)
+}
+
+// GetBlocked - get transacts which delay is over, but destination was busy
+func (obj *Advance) GetBlocked() []*Transaction {
+	var blocked []*Transaction
+	for _, tr := range obj.tb.List() {
+		if tr.transact.IsTheEnd() {
+			blocked = append(blocked, tr.transact)
 		}
-	}()
+	}
+	return blocked
+}
+
+// Release - try to send blocked transact to destination
+func (obj *Advance) Release(transact *Transaction) bool {
+	if obj.tb.Item(transact.GetID()) == nil || !transact.IsTheEnd() {
+		return false
+	}
+	return obj.HandleTransact(transact)
+}
+
+// HandleTransacts handle transacts, tries to move blocked transacts
+func (obj *Advance) HandleTransacts(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for _, transact := range obj.GetBlocked() {
+		obj.Release(transact)
+	}
 }
 
 // AppendTransact append transact to object
 func (obj *Advance) AppendTransact(transact *Transaction) bool {
 	obj.BaseObj.AppendTransact(transact)
+	if obj.tb.Item(transact.GetID()) != nil {
+		// Transact re-enters advance from destination of advance, for example
+		// in rework loop
+		obj.tb.Remove(transact)
+	}
 	transact.SetHolder(obj.name)
-	advance := obj.GenerateAdvance()
+	advance := obj.HandleAdvance(obj, transact)
+	if advance < 0 {
+		advance = 0
+	}
 	obj.sumAdvance += float64(advance)
 	transact.SetTiсks(advance)
 	obj.tb.Push(transact)
 	obj.sumTransact++
+	obj.events[transact.GetID()] = obj.Pipe.Schedule(obj, transact, advance)
+	return true
+}
+
+// Suspend - interrupt delay of transact, transact leaves advance and remaining
+// ticks are saved in transact
+func (obj *Advance) Suspend(transact *Transaction) bool {
+	e := obj.events[transact.GetID()]
+	if e == nil || !e.IsScheduled() {
+		return false
+	}
+	obj.Pipe.Cancel(e)
+	remaining := e.Time - obj.Pipe.ModelTime
+	transact.SetParameter("ticks", remaining)
+	transact.SetParameter("advance", transact.GetAdvanceTime()-remaining)
+	obj.tb.Remove(transact)
+	delete(obj.events, transact.GetID())
 	return true
 }
 
+// Resume - continue delay of interrupted transact with remaining ticks
+func (obj *Advance) Resume(transact *Transaction) {
+	transact.SetHolder(obj.name)
+	transact.SetTiсks(transact.GetTicks())
+	obj.tb.Push(transact)
+	obj.events[transact.GetID()] = obj.Pipe.Schedule(obj, transact, transact.GetTicks())
+}
+
 // Report - print report about object
 func (obj *Advance) Report() {
 	obj.BaseObj.Report()
 	fmt.Printf("Average advance %.2f\n", obj.sumAdvance/obj.sumTransact)
 	fmt.Println()
 }
+
+// Stats - get statistics of object
+func (obj *Advance) Stats() map[string]float64 {
+	return map[string]float64{
+		"average_advance": ratio(obj.sumAdvance, obj.sumTransact),
+		"entries":         obj.sumTransact,
+		"current_content": float64(obj.tb.Len()),
+	}
+}
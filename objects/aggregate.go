@@ -86,10 +86,18 @@ func (obj *Aggregate) Report() {
 	fmt.Printf("Number of aggregated transact %.2f\n", obj.sumTransact)
 	if obj.tb.Len() > 0 {
 		fmt.Println("Await end aggregate:")
-		for _, item := range obj.tb.Items() {
+		for _, item := range obj.tb.List() {
 			_, parts, _ := item.transact.GetParts()
 			fmt.Printf("transact %d wait %d parts\n", item.transact.GetID(), parts)
 		}
 	}
 	fmt.Println()
 }
+
+// Stats - get statistics of object
+func (obj *Aggregate) Stats() map[string]float64 {
+	return map[string]float64{
+		"aggregated":      obj.sumTransact,
+		"current_content": float64(obj.tb.Len()),
+	}
+}
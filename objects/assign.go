@@ -4,36 +4,174 @@
 
 package objects
 
+import (
+	"fmt"
+	"math"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+// AssignMode defines how Assign modifies parameter, as ASSIGN A+ and A- in GPSS
+type AssignMode int
+
+const (
+	// AssignSet - parameter is replaced by value, it is default mode
+	AssignSet AssignMode = iota
+	// AssignIncrement - value is added to parameter
+	AssignIncrement
+	// AssignDecrement - value is subtracted from parameter
+	AssignDecrement
+	// AssignMultiply - parameter is multiplied by value
+	AssignMultiply
+)
+
+// AssignFunc is a function signature for value of parameter computed from
+// transact
+type AssignFunc func(transact *Transaction) interface{}
+
+// Assignment is a modification of parameter of transact. Value is a constant,
+// *Expr, *Function, distributions.Distribution or AssignFunc, it is evaluated
+// for each transact. Missing parameter is treated as 0 by arithmetic modes.
+type Assignment struct {
+	Name  string      // Name of parameter
+	Mode  AssignMode  // Mode of modification
+	Value interface{} // Value or source of value
+}
+
 // Assign - modify Transaction Parameters of Active Transaction
 type Assign struct {
 	BaseObj
-	// Parameters for modification
-	parameters []Parameter
+	// Modifications of parameters
+	assignments []Assignment
+	// Number of random stream for distributions, RN1 by default
+	Stream int
 }
 
-// NewAssign creates new Assign.
+// NewAssign creates new Assign, which sets parameters.
 // name - name of object
 // parameters - parameters for assign.
 // Example:
 // Parameter{name: "param1_name", value: param1_value},
 // Parameter{name: "param2_name", value: param2_value} ...
 func NewAssign(name string, parameters ...Parameter) *Assign {
-	obj := &Assign{parameters: parameters}
+	assignments := make([]Assignment, len(parameters))
+	for i, p := range parameters {
+		assignments[i] = Assignment{Name: p.Name, Value: p.Value}
+	}
+	return NewAssignments(name, assignments...)
+}
+
+// NewAssignments creates new Assign with arithmetic modes.
+// name - name of object; assignments - modifications of parameters.
+// Example:
+// Assignment{Name: "loops", Mode: AssignIncrement, Value: 1},
+// Assignment{Name: "cost", Mode: AssignIncrement, Value: distributions.NewNormal(10, 2)}
+func NewAssignments(name string, assignments ...Assignment) *Assign {
+	obj := &Assign{assignments: assignments}
 	obj.name = name
 	return obj
 }
 
-// AppendTransact append transact to object
+// start - resolve entities of expressions of assignments, unknown entity stops
+// simulation with model error before the first event
+func (obj *Assign) start() {
+	for _, a := range obj.assignments {
+		if e, ok := a.Value.(*Expr); ok {
+			if err := e.resolve(obj.Pipe); err != nil {
+				panic(fmt.Errorf("assign %q: %w", a.Name, err))
+			}
+		}
+	}
+}
+
+// AppendTransact append transact to object. Parameters are modified before
+// transact goes to destination, so destination sees new values. Assignments
+// are applied in order, each assignment sees results of previous ones.
+// Parameters are restored if destination refuses transact.
 func (obj *Assign) AppendTransact(transact *Transaction) bool {
 	obj.BaseObj.AppendTransact(transact)
+	saved := make([]Parameter, len(obj.assignments))
+	for i, a := range obj.assignments {
+		saved[i] = Parameter{Name: a.Name, Value: transact.GetParameter(a.Name)}
+		value := obj.apply(a, saved[i].Value, obj.value(a, transact))
+		transact.SetParameters([]Parameter{{Name: a.Name, Value: value}})
+	}
 	for _, v := range obj.GetDst() {
 		if v.AppendTransact(transact) {
-			transact.SetParameters(obj.parameters)
 			return true
 		}
 	}
+	for i := len(saved) - 1; i >= 0; i-- {
+		transact.SetParameters(saved[i : i+1])
+	}
 	return false
 }
 
+// value - evaluate value of assignment for transact, error of evaluation stops
+// simulation with model error
+func (obj *Assign) value(a Assignment, transact *Transaction) interface{} {
+	switch v := a.Value.(type) {
+	case *Expr:
+		res, err := v.Eval(transact)
+		if err != nil {
+			panic(err)
+		}
+		return res
+	case *Function:
+		return wholeToInt(v.Evaluate(transact))
+	case distributions.Distribution:
+		return wholeToInt(v.Sample(obj.Pipe.RN(obj.Stream)))
+	case AssignFunc:
+		return v(transact)
+	case func(transact *Transaction) interface{}:
+		return v(transact)
+	}
+	return a.Value
+}
+
+// apply - get new value of parameter by mode of assignment
+func (obj *Assign) apply(a Assignment, old, value interface{}) interface{} {
+	if a.Mode == AssignSet {
+		return value
+	}
+	if old == nil {
+		old = 0
+	}
+	delta, ok := toFloat64(value)
+	cur, okOld := toFloat64(old)
+	if !ok || !okOld {
+		panic(fmt.Sprintf("assign %q: invalid operands %v and %v", a.Name, old, value))
+	}
+	oi, oldInt := old.(int)
+	vi, valueInt := value.(int)
+	switch a.Mode {
+	case AssignIncrement:
+		if oldInt && valueInt {
+			return oi + vi
+		}
+		return cur + delta
+	case AssignDecrement:
+		if oldInt && valueInt {
+			return oi - vi
+		}
+		return cur - delta
+	case AssignMultiply:
+		if oldInt && valueInt {
+			return oi * vi
+		}
+		return cur * delta
+	}
+	panic(fmt.Sprintf("assign %q: unknown mode %d", a.Name, a.Mode))
+}
+
+// wholeToInt - convert whole number to int, for example sample of discrete
+// distribution
+func wholeToInt(v float64) interface{} {
+	if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+		return int(v)
+	}
+	return v
+}
+
 // Report - print report about object
 func (obj *Assign) Report() {}
@@ -0,0 +1,76 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+func TestAssign_Modes(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	assign := NewAssignments("assign",
+		Assignment{Name: "loops", Mode: AssignIncrement, Value: 1},
+		Assignment{Name: "cost", Mode: AssignIncrement, Value: distributions.NewConstant(2.5)},
+		Assignment{Name: "stock", Mode: AssignDecrement, Value: MustCompileExpr("P$loops + 1")},
+		Assignment{Name: "weight", Mode: AssignMultiply, Value: 3},
+		Assignment{Name: "id", Value: AssignFunc(func(transact *Transaction) interface{} { return transact.GetID() })},
+	)
+	hole := NewHole("hole")
+	pipe.Append(assign, hole)
+	pipe.Append(hole)
+	transact := NewTransaction(pipe)
+	transact.SetParameters([]Parameter{{Name: "stock", Value: 10}, {Name: "weight", Value: 1.5}})
+	assign.AppendTransact(transact)
+	expected := map[string]interface{}{"loops": 1, "cost": 2.5, "stock": 8, "weight": 4.5, "id": transact.GetID()}
+	for name, value := range expected {
+		if transact.GetParameter(name) != value {
+			t.Error("Parameter", name, "expected", value, "got", transact.GetParameter(name))
+		}
+	}
+}
+
+func TestAssign_BeforeRouting(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 1, nil)
+	rework := NewAssignments("rework", Assignment{Name: "loops", Mode: AssignIncrement, Value: 1})
+	work := NewAdvance("work", 5, 0)
+	check, err := NewCheckExpr("done?", "P$loops >= 3", rework)
+	if err != nil {
+		t.Fatal("Check, expected", nil, "got", err)
+	}
+	hole := NewHole("hole")
+	pipe.Append(gen, rework)
+	pipe.Append(rework, work)
+	pipe.Append(work, check)
+	pipe.Append(check, hole)
+	pipe.Append(hole)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if hole.cntTransact != 1 || hole.sumLife != 15 {
+		t.Error("Life of transact after 3 loops, expected", 15, "got", hole.sumLife)
+	}
+}
+
+func TestAssign_RestoreOnRefuse(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	assign := NewAssignments("assign", Assignment{Name: "loops", Mode: AssignIncrement, Value: 1})
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(assign, facility)
+	pipe.Append(facility)
+	facility.AppendTransact(NewTransaction(pipe))
+	transact := NewTransaction(pipe)
+	for i := 0; i < 3; i++ {
+		if assign.AppendTransact(transact) {
+			t.Fatal("Transact accepted by busy facility")
+		}
+	}
+	if transact.GetParameter("loops") != nil {
+		t.Error("Parameter after refuse, expected", nil, "got", transact.GetParameter("loops"))
+	}
+}
@@ -22,10 +22,25 @@ type IBaseObj interface {
 	SetPipeline(pipe *Pipeline)            // Set pipeline for object
 	AppendTransact(*Transaction) bool      // Append transact to object
 	HandleTransacts(wg *sync.WaitGroup)    // Handle all transacts of object
+	HandleEvent(e *Event)                  // Handle event from future events chain
 	Report()                               // Print report
 	LinkObject(obj ...IBaseObj) []IBaseObj // Link current object with new obj
 }
 
+// IBlocking implements interface of object which holds transactions that are
+// ready to leave object, but their destination was busy
+type IBlocking interface {
+	GetBlocked() []*Transaction         // Get blocked transacts in order of object
+	Release(transact *Transaction) bool // Try to send blocked transact to destination
+}
+
+// ISuspendable implements interface of object which can interrupt delay of
+// transaction, it is used by preemption of facility
+type ISuspendable interface {
+	Suspend(transact *Transaction) bool // Interrupt delay, transact leaves object
+	Resume(transact *Transaction)       // Continue delay with remaining ticks
+}
+
 // BaseObj is the base object of simulation system
 type BaseObj struct {
 	name string
@@ -39,10 +54,8 @@ type BaseObj struct {
 func (obj *BaseObj) LinkObject(objs ...IBaseObj) []IBaseObj {
 	obj.SetDst(objs...)
 	for _, o := range objs {
-		if _, ok := obj.Pipe.objects[o.GetName()]; !ok {
-			o.SetPipeline(obj.Pipe)
-			o.SetID(len(obj.Pipe.objects))
-			obj.Pipe.objects[o.GetName()] = o
+		if obj.Pipe.GetObjByName(o.GetName()) == nil {
+			obj.Pipe.addObject(o)
 		}
 	}
 
@@ -88,6 +101,9 @@ func (obj *BaseObj) GetID() int {
 // AppendTransact append transact to object
 func (obj *BaseObj) AppendTransact(t *Transaction) bool {
 	utils.Log.Trace.Println("Append transact ", t.GetID(), " to ", obj.name)
+	if obj.Pipe != nil {
+		obj.Pipe.touch(obj.name)
+	}
 	return true
 }
 
@@ -96,6 +112,9 @@ func (obj *BaseObj) HandleTransacts(wg *sync.WaitGroup) {
 	wg.Done()
 }
 
+// HandleEvent handle event from future events chain
+func (obj *BaseObj) HandleEvent(e *Event) {}
+
 // Report - print report about object
 func (obj *BaseObj) Report() {
 	fmt.Println("Object name \"", obj.name, "\"")
@@ -9,11 +9,19 @@ package objects
 
 import (
 	"fmt"
+	"sync"
+
+	utils "github.com/soldatov-s/go-gpss/internal"
 )
 
+// HandleInFacilityFunc is a function signature for generate advance of
+// transact in InFacility
+type HandleInFacilityFunc func(obj *InFacility, transact *Transaction) int
+
 // InFacility is the first part of a Bifacility, it takes ownership of a Facility
 type InFacility struct {
 	BaseObj
+	availability
 	// Holded transast ID
 	HoldedTransactID int
 	// For backuping Facility/Bifacility name if we includes Bifacility in Bifacility
@@ -24,6 +32,23 @@ type InFacility struct {
 	sumAdvance float64
 	// For saving time of input transact in Bifacility
 	timeOfInput int
+	// Preemptive mode, transact with higher priority interrupts holded
+	// transact, if holded transact is delayed in object which can suspend it
+	Preemptive bool
+	// Destination of interrupted transacts, if nil interrupted transact
+	// waits and resumes after the preemptor leaves facility
+	PreemptDst IBaseObj
+	// Interrupted transacts, waiting for resume
+	suspended *TransactTable
+	// For counting the preemptions
+	cntPreempt float64
+	// Function for generate advance of transact in InFacility, if nil
+	// transact goes to destination at once
+	HandleAdvance HandleInFacilityFunc
+	// Scheduled end of advance of holded transact
+	event *Event
+	// State of facility, 1 if busy, weighted by time
+	busy timeWeighted
 }
 
 // OutFacility is the second part of a Bifacility, for release ownership of a Facility
@@ -38,6 +63,7 @@ type OutFacility struct {
 func NewBifacility(name string) (*InFacility, *OutFacility) {
 	inObj := &InFacility{}
 	inObj.BaseObj.Init(name)
+	inObj.suspended = NewTransactTable()
 	outObj := &OutFacility{}
 	outObj.name = name + "_OUT"
 	outObj.tb = inObj.tb
@@ -45,47 +71,206 @@ func NewBifacility(name string) (*InFacility, *OutFacility) {
 	return inObj, outObj
 }
 
-// HandleTransact handle transact
-func (obj *InFacility) HandleTransact(transact *Transaction) {
+// HandleTransact handle transact, transact goes to destination. Returns true
+// if transact leaved InFacility.
+func (obj *InFacility) HandleTransact(transact *Transaction) bool {
 	transact.PrintInfo()
 	for _, v := range obj.GetDst() {
 		if v.AppendTransact(transact) {
-			return
+			return true
 		}
 	}
+	return false
 }
 
-// AppendTransact append transact to object
+// HandleEvent handle the end of advance of transact
+func (obj *InFacility) HandleEvent(e *Event) {
+	if obj.event != e {
+		// Transact already left InFacility
+		return
+	}
+	e.Transact.ResetTicks()
+	obj.HandleTransact(e.Transact)
+}
+
+// GetBlocked - get holded transact, if its advance is over, but destination
+// was busy
+func (obj *InFacility) GetBlocked() []*Transaction {
+	item := obj.tb.Item(obj.HoldedTransactID)
+	if item == nil || item.transact.GetHolder() != obj.name ||
+		(obj.event != nil && obj.event.IsScheduled()) {
+		return nil
+	}
+	return []*Transaction{item.transact}
+}
+
+// Release - try to send blocked transact to destination
+func (obj *InFacility) Release(transact *Transaction) bool {
+	blocked := obj.GetBlocked()
+	if len(blocked) == 0 || blocked[0] != transact {
+		return false
+	}
+	return obj.HandleTransact(transact)
+}
+
+// HandleTransacts handle transacts, tries to move blocked transact
+func (obj *InFacility) HandleTransacts(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for _, transact := range obj.GetBlocked() {
+		obj.Release(transact)
+	}
+}
+
+// AppendTransact append transact to object. In preemptive mode transact with
+// higher priority interrupts holded transact. Unavailable facility refuses
+// all transacts.
 func (obj *InFacility) AppendTransact(transact *Transaction) bool {
-	if obj.tb.Len() != 0 {
+	if !obj.IsAvailable() {
+		return false
+	}
+	if obj.tb.Len() != 0 && !obj.preempt(transact) {
 		// Facility is busy
 		return false
 	}
 	obj.BaseObj.AppendTransact(transact)
-	transact.SetHolder(obj.name)
-	if transact.GetParameter("Facility") != nil {
-		obj.bakupFacilityName = transact.GetParameter("Facility").(string)
+	obj.cntTransact++
+	obj.hold(transact)
+	if obj.HandleAdvance != nil {
+		advance := obj.HandleAdvance(obj, transact)
+		if advance < 0 {
+			advance = 0
+		}
+		transact.SetTiсks(advance)
+		obj.event = obj.Pipe.Schedule(obj, transact, advance)
+		return true
 	}
+	obj.HandleTransact(transact)
+	return true
+}
+
+// Suspend - interrupt advance of transact in InFacility, remaining ticks are
+// saved in transact
+func (obj *InFacility) Suspend(transact *Transaction) bool {
+	if obj.event == nil || obj.event.Transact != transact || !obj.event.IsScheduled() {
+		return false
+	}
+	obj.Pipe.Cancel(obj.event)
+	remaining := obj.event.Time - obj.Pipe.ModelTime
+	transact.SetParameter("ticks", remaining)
+	transact.SetParameter("advance", transact.GetAdvanceTime()-remaining)
+	return true
+}
+
+// Resume - continue advance of interrupted transact with remaining ticks
+func (obj *InFacility) Resume(transact *Transaction) {
+	transact.SetHolder(obj.name)
+	transact.SetTiсks(transact.GetTicks())
+	obj.event = obj.Pipe.Schedule(obj, transact, transact.GetTicks())
+}
+
+// hold - take ownership of facility by transact
+func (obj *InFacility) hold(transact *Transaction) {
+	transact.SetHolder(obj.name)
+	obj.bakupFacilityName = GetOr(transact, "Facility", "")
 	transact.SetParameter("Facility", obj.name)
 	obj.HoldedTransactID = transact.GetID()
 	obj.tb.Push(transact)
-	obj.cntTransact++
 	obj.timeOfInput = obj.Pipe.ModelTime
-	obj.HandleTransact(transact)
+	obj.busy.set(obj.Pipe.ModelTime, 1)
+}
+
+// preempt - interrupt holded transact, if facility is preemptive, transact
+// has higher priority than holded transact and holded transact is delayed in
+// object which can suspend it. Returns true if facility was released.
+func (obj *InFacility) preempt(transact *Transaction) bool {
+	item := obj.tb.Item(obj.HoldedTransactID)
+	if !obj.Preemptive || item == nil || transact.GetPriority() <= item.transact.GetPriority() {
+		return false
+	}
+	if !obj.interrupt(obj.PreemptDst) {
+		return false
+	}
+	obj.cntPreempt++
+	return true
+}
+
+// interrupt - interrupt holded transact, if it is delayed in object which can
+// suspend it, and send it to destination. If destination is nil or refuses
+// it, transact waits for resume. Returns true if transact was interrupted.
+func (obj *InFacility) interrupt(dst IBaseObj) bool {
+	holded := obj.tb.Item(obj.HoldedTransactID).transact
+	holder, ok := obj.Pipe.GetObjByName(holded.GetHolder()).(ISuspendable)
+	if !ok || !holder.Suspend(holded) {
+		return false
+	}
+	if obj.bakupFacilityName != "" {
+		holded.SetParameter("Facility", obj.bakupFacilityName)
+	} else {
+		holded.SetParameter("Facility", nil)
+	}
+	obj.sumAdvance += float64(obj.Pipe.ModelTime - obj.timeOfInput)
+	obj.tb.Remove(holded)
+	obj.HoldedTransactID = -1
+	obj.busy.set(obj.Pipe.ModelTime, 0)
+	utils.Log.Trace.Println("Interrupt transact ", holded.GetID(), " in ", obj.name)
+	if dst != nil && dst.AppendTransact(holded) {
+		return true
+	}
+	obj.suspended.PushByPriority(holded)
 	return true
 }
 
+// resume - interrupted transact with highest priority takes ownership of
+// available facility and continues delay in object where it was interrupted
+func (obj *InFacility) resume() {
+	if !obj.IsAvailable() {
+		return
+	}
+	transact := obj.suspended.Pop()
+	if transact == nil {
+		return
+	}
+	utils.Log.Trace.Println("Resume transact ", transact.GetID(), " in ", obj.name)
+	holder := obj.Pipe.GetObjByName(transact.GetHolder()).(ISuspendable)
+	obj.hold(transact)
+	holder.Resume(transact)
+}
+
+// SetUnavailable - facility becomes unavailable, holded transact is
+// interrupted or rerouted to destination according to policy, if it is
+// delayed in object which can suspend it
+func (obj *InFacility) SetUnavailable(policy BreakdownPolicy, dst IBaseObj) {
+	if !obj.fail(obj.Pipe.ModelTime) || policy == BreakdownContinue ||
+		obj.tb.Item(obj.HoldedTransactID) == nil {
+		return
+	}
+	if policy != BreakdownReroute {
+		dst = nil
+	}
+	obj.interrupt(dst)
+}
+
+// SetAvailable - facility becomes available, interrupted transact resumes
+func (obj *InFacility) SetAvailable() {
+	if obj.repair(obj.Pipe.ModelTime) && obj.tb.Len() == 0 {
+		obj.resume()
+	}
+}
+
 // Report - print report about object
 func (obj *InFacility) Report() {
 	obj.BaseObj.Report()
-	avr := obj.sumAdvance / obj.cntTransact
-	fmt.Printf("Average advance %.2f \tAverage utilization %.2f%%\tNumber entries %.2f \t", avr,
-		100*avr*obj.cntTransact/float64(obj.Pipe.SimTime), obj.cntTransact)
+	fmt.Printf("Average advance %.2f \tAverage utilization %.2f%%\tNumber entries %.2f \t",
+		obj.sumAdvance/obj.cntTransact, 100*obj.busy.average(obj.Pipe.ModelTime), obj.cntTransact)
 	if obj.HoldedTransactID > 0 {
 		fmt.Print("Transact ", obj.HoldedTransactID, " in facility")
 	} else {
 		fmt.Print("Facility is empty")
 	}
+	if obj.Preemptive {
+		fmt.Printf("\tPreemptions %.2f\tSuspended %d", obj.cntPreempt, obj.suspended.Len())
+	}
+	obj.reportAvailability(obj.Pipe.ModelTime)
 	fmt.Printf("\n\n")
 }
 
@@ -112,6 +297,8 @@ func (obj *OutFacility) HandleTransact(transact *Transaction) {
 		obj.inFacility.sumAdvance += float64(advance)
 		obj.tb.Remove(transact)
 		obj.inFacility.HoldedTransactID = -1
+		obj.inFacility.busy.set(obj.Pipe.ModelTime, 0)
+		obj.inFacility.resume()
 		return
 	}
 	transact.SetParameters([]Parameter{{Name: "Facility", Value: obj.name}})
@@ -129,3 +316,16 @@ func (obj *OutFacility) AppendTransact(transact *Transaction) bool {
 
 // Report - print report about object
 func (obj *OutFacility) Report() {}
+
+// Stats - get statistics of object
+func (obj *InFacility) Stats() map[string]float64 {
+	return obj.statsAvailability(map[string]float64{
+		"average_advance": ratio(obj.sumAdvance, obj.cntTransact),
+		"average_time":    ratio(obj.busy.area(obj.Pipe.ModelTime), obj.cntTransact),
+		"utilization":     100 * obj.busy.average(obj.Pipe.ModelTime),
+		"entries":         obj.cntTransact,
+		"current_content": float64(obj.tb.Len()),
+		"preemptions":     obj.cntPreempt,
+		"suspended":       float64(obj.suspended.Len()),
+	}, obj.Pipe.ModelTime)
+}
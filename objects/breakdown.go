@@ -0,0 +1,269 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+	utils "github.com/soldatov-s/go-gpss/internal"
+)
+
+// BreakdownPolicy defines what happens with holded transaction, when server
+// becomes unavailable
+type BreakdownPolicy int
+
+const (
+	// BreakdownContinue - holded transact continues service, new transacts
+	// are refused until repair
+	BreakdownContinue BreakdownPolicy = iota
+	// BreakdownInterrupt - service of holded transact is interrupted and
+	// resumed after repair
+	BreakdownInterrupt
+	// BreakdownReroute - service of holded transact is interrupted and
+	// transact is sent to reroute destination
+	BreakdownReroute
+)
+
+var (
+	// ErrBreakdownPolicy - server does not support policy of breakdown
+	ErrBreakdownPolicy = errors.New("breakdown policy is not supported")
+	// ErrNoRerouteDst - policy is BreakdownReroute, but RerouteDst is not set
+	ErrNoRerouteDst = errors.New("reroute destination is not set")
+	// ErrBreakdownCalendar - calendar has downtime with negative start or
+	// duration, or downtimes are not ordered or overlap
+	ErrBreakdownCalendar = errors.New("invalid breakdown calendar")
+)
+
+// IAvailability implements interface of server which can become unavailable
+type IAvailability interface {
+	SetUnavailable(policy BreakdownPolicy, dst IBaseObj) // Server becomes unavailable (FUNAVAIL)
+	SetAvailable()                                       // Server becomes available (FAVAIL)
+	IsAvailable() bool                                   // Check that server is available
+}
+
+// availability keeps state and statistics of availability of server
+type availability struct {
+	unavailable   int     // Number of active failures, server is available if zero
+	timeOfFailure int     // Model time of last failure
+	sumDowntime   int     // Total downtime
+	cntFailures   float64 // For counting the failures
+}
+
+// IsAvailable check that server is available
+func (a *availability) IsAvailable() bool {
+	return a.unavailable == 0
+}
+
+// fail - server becomes unavailable at model time. Returns true if server was
+// available before.
+func (a *availability) fail(modelTime int) bool {
+	a.unavailable++
+	if a.unavailable > 1 {
+		return false
+	}
+	a.timeOfFailure = modelTime
+	a.cntFailures++
+	return true
+}
+
+// repair - server becomes available at model time, if all failures are
+// repaired. Returns true if server became available.
+func (a *availability) repair(modelTime int) bool {
+	if a.unavailable == 0 {
+		return false
+	}
+	a.unavailable--
+	if a.unavailable > 0 {
+		return false
+	}
+	a.sumDowntime += modelTime - a.timeOfFailure
+	return true
+}
+
+// downtime - get total downtime including current failure
+func (a *availability) downtime(modelTime int) int {
+	if a.unavailable > 0 {
+		return a.sumDowntime + modelTime - a.timeOfFailure
+	}
+	return a.sumDowntime
+}
+
+// reportAvailability - print availability of server, it is a share of
+// simulated time when server was available
+func (a *availability) reportAvailability(modelTime int) {
+	if a.cntFailures == 0 {
+		return
+	}
+	downtime := a.downtime(modelTime)
+	fmt.Printf("\tAvailability %.2f%%\tDowntime %d\tFailures %.2f",
+		100-100*ratio(float64(downtime), float64(modelTime)), downtime, a.cntFailures)
+}
+
+// statsAvailability - add availability of server to statistics
+func (a *availability) statsAvailability(stats map[string]float64, modelTime int) map[string]float64 {
+	downtime := a.downtime(modelTime)
+	stats["availability"] = 100 - 100*ratio(float64(downtime), float64(modelTime))
+	stats["downtime"] = float64(downtime)
+	stats["failures"] = a.cntFailures
+	return stats
+}
+
+// Downtime is a window of scheduled unavailability
+type Downtime struct {
+	Start    int // Model time of start of downtime
+	Duration int // Duration of downtime
+}
+
+// Breakdown is a failure and repair process of server (Facility, Bifacility or
+// Storage). Time between failures and repair time are drawn as Interval ±
+// Modificator, or downtimes are taken from a fixed calendar.
+type Breakdown struct {
+	BaseObj
+	target             IAvailability              // Server
+	Interval           int                        // The mean time between failures
+	Modificator        int                        // The time between failures half-range
+	RepairInterval     int                        // The mean repair time
+	RepairModificator  int                        // The repair time half-range
+	Distribution       distributions.Distribution // Distribution of time between failures, used instead of Interval and Modificator if set
+	RepairDistribution distributions.Distribution // Distribution of repair time, used instead of RepairInterval and RepairModificator if set
+	Stream             int                        // Number of random stream, RN1 by default
+	Policy             BreakdownPolicy            // Policy for holded transact
+	RerouteDst         IBaseObj                   // Destination of transacts for BreakdownReroute
+	Calendar           []Downtime                 // Calendar of downtimes, it is used instead of random failures
+	Period             int                        // Period of repeating calendar, 0 - calendar is not repeated
+	calendarIdx        int                        // Index of next downtime in calendar
+	calendarCycle      int                        // Number of repeating of calendar
+	repairTime         int                        // Model time of end of current downtime
+	down               bool                       // Server is down by this breakdown
+}
+
+// NewBreakdown creates new Breakdown with random failures.
+// name - name of object; target - server; interval - the mean time between
+// failures; modificator - the time between failures half-range; repair - the
+// mean repair time; repairModificator - the repair time half-range; policy -
+// policy for holded transact
+func NewBreakdown(name string, target IAvailability, interval, modificator,
+	repair, repairModificator int, policy BreakdownPolicy) *Breakdown {
+	obj := &Breakdown{}
+	obj.name = name
+	obj.target = target
+	obj.Interval = interval
+	obj.Modificator = modificator
+	obj.RepairInterval = repair
+	obj.RepairModificator = repairModificator
+	obj.Policy = policy
+	return obj
+}
+
+// NewBreakdownCalendar creates new Breakdown with downtimes from calendar.
+// name - name of object; target - server; period - period of repeating
+// calendar, 0 if calendar is not repeated; policy - policy for holded
+// transact; calendar - downtimes
+func NewBreakdownCalendar(name string, target IAvailability, period int,
+	policy BreakdownPolicy, calendar ...Downtime) *Breakdown {
+	obj := &Breakdown{}
+	obj.name = name
+	obj.target = target
+	obj.Period = period
+	obj.Policy = policy
+	obj.Calendar = calendar
+	return obj
+}
+
+// start - schedule the first failure when simulation starts. Policy which is
+// not supported by server, BreakdownReroute without RerouteDst and invalid
+// calendar stop simulation with model error.
+func (obj *Breakdown) start() {
+	if err := obj.check(); err != nil {
+		obj.Pipe.Fail(fmt.Errorf("breakdown %q: %w", obj.name, err))
+		return
+	}
+	obj.scheduleFailure()
+}
+
+// check - check policy and calendar of breakdown
+func (obj *Breakdown) check() error {
+	if c, ok := obj.target.(interface{ checkPolicy(BreakdownPolicy) error }); ok {
+		if err := c.checkPolicy(obj.Policy); err != nil {
+			return err
+		}
+	}
+	if obj.Policy == BreakdownReroute && obj.RerouteDst == nil {
+		return ErrNoRerouteDst
+	}
+	end := 0
+	for i, d := range obj.Calendar {
+		if d.Start < end || d.Duration < 0 {
+			return fmt.Errorf("%w: downtime %d from %d for %d", ErrBreakdownCalendar, i, d.Start, d.Duration)
+		}
+		end = d.Start + d.Duration
+	}
+	if len(obj.Calendar) > 0 && obj.Period > 0 && end > obj.Calendar[0].Start+obj.Period {
+		return fmt.Errorf("%w: downtimes are longer than period %d", ErrBreakdownCalendar, obj.Period)
+	}
+	return nil
+}
+
+// generate - generate time by distribution, if it is nil by interval and
+// modificator
+func (obj *Breakdown) generate(d distributions.Distribution, interval, modificator int) int {
+	if d != nil {
+		return distributions.Int(d, obj.Pipe.RN(obj.Stream))
+	}
+	if modificator > 0 {
+		interval += utils.GetRandomFrom(obj.Pipe.RN(obj.Stream), -modificator, modificator)
+	}
+	return interval
+}
+
+// scheduleFailure - schedule next failure of server
+func (obj *Breakdown) scheduleFailure() {
+	if obj.Calendar == nil {
+		obj.repairTime = -1
+		obj.Pipe.Schedule(obj, nil, obj.generate(obj.Distribution, obj.Interval, obj.Modificator))
+		return
+	}
+	if obj.calendarIdx >= len(obj.Calendar) {
+		if obj.Period <= 0 {
+			return
+		}
+		obj.calendarIdx = 0
+		obj.calendarCycle++
+	}
+	downtime := obj.Calendar[obj.calendarIdx]
+	start := downtime.Start + obj.calendarCycle*obj.Period
+	obj.repairTime = start + downtime.Duration
+	obj.calendarIdx++
+	obj.Pipe.Schedule(obj, nil, start-obj.Pipe.ModelTime)
+}
+
+// HandleEvent handle failure or repair of server
+func (obj *Breakdown) HandleEvent(e *Event) {
+	if !obj.down {
+		utils.Log.Trace.Println("Failure of ", obj.name)
+		obj.down = true
+		obj.target.SetUnavailable(obj.Policy, obj.RerouteDst)
+		repair := obj.repairTime - obj.Pipe.ModelTime
+		if obj.Calendar == nil {
+			repair = obj.generate(obj.RepairDistribution, obj.RepairInterval, obj.RepairModificator)
+		}
+		obj.Pipe.Schedule(obj, nil, repair)
+		return
+	}
+	utils.Log.Trace.Println("Repair of ", obj.name)
+	obj.down = false
+	obj.target.SetAvailable()
+	obj.scheduleFailure()
+}
+
+// AppendTransact breakdown is not a block
+func (obj *Breakdown) AppendTransact(transact *Transaction) bool {
+	return false
+}
+
+// Report - print report about object
+func (obj *Breakdown) Report() {}
@@ -0,0 +1,156 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newBreakdownPipeline(policy BreakdownPolicy, facility ...IBaseObj) (*Pipeline, *Breakdown, *Hole) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	hole := NewHole("hole")
+	pipe.AddObject(NewGenerator("gen", 0, 0, 0, 1, nil))
+	for _, obj := range facility {
+		pipe.AddObject(obj)
+	}
+	pipe.AddObject(hole)
+	// Server is down from 4 to 10
+	breakdown := NewBreakdownCalendar("breakdown", facility[0].(IAvailability), 0,
+		policy, Downtime{Start: 4, Duration: 6})
+	pipe.Append(breakdown)
+	return pipe, breakdown, hole
+}
+
+func TestBreakdown_Policy(t *testing.T) {
+	tests := []struct {
+		policy   BreakdownPolicy
+		sumLife  float64
+		rerouted float64
+	}{
+		{BreakdownContinue, 10, 0},
+		{BreakdownInterrupt, 16, 0},
+		{BreakdownReroute, 0, 1},
+	}
+	for _, tt := range tests {
+		facility := NewFacility("facility", 10, 0)
+		pipe, breakdown, hole := newBreakdownPipeline(tt.policy, facility)
+		rerouted := NewHole("rerouted")
+		breakdown.RerouteDst = rerouted
+		pipe.Append(rerouted)
+		res, err := pipe.Run(context.Background(), 100)
+		if err != nil {
+			t.Fatal("Run error, expected", nil, "got", err)
+		}
+		if hole.sumLife != tt.sumLife {
+			t.Error("Sum life, policy", tt.policy, "expected", tt.sumLife, "got", hole.sumLife)
+		}
+		if rerouted.cntTransact != tt.rerouted {
+			t.Error("Rerouted transacts, policy", tt.policy, "expected", tt.rerouted, "got", rerouted.cntTransact)
+		}
+		stats := res.Object("facility").Stats
+		if stats["downtime"] != 6 || stats["failures"] != 1 || stats["availability"] != 94 {
+			t.Error("Availability stats, expected", 6, 1, 94, "got",
+				stats["downtime"], stats["failures"], stats["availability"])
+		}
+	}
+}
+
+func TestBreakdown_InFacility(t *testing.T) {
+	in, out := NewBifacility("facility")
+	pipe, _, hole := newBreakdownPipeline(BreakdownInterrupt, in, NewAdvance("service", 10, 0), out)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if hole.cntTransact != 1 || hole.sumLife != 16 {
+		t.Error("Sum life, expected", 16, "got", hole.sumLife)
+	}
+	if in.sumAdvance != 10 {
+		t.Error("Sum advance, expected", 10, "got", in.sumAdvance)
+	}
+}
+
+func TestBreakdown_Refuse(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	facility := NewFacility("facility", 1, 0)
+	hole := NewHole("hole")
+	pipe.AddObject(NewGenerator("gen", 1, 0, 0, 0, nil)).
+		AddObject(NewQueue("queue")).
+		AddObject(facility).
+		AddObject(hole)
+	// Facility is down 50 ticks in every 100 ticks
+	pipe.Append(NewBreakdownCalendar("breakdown", facility, 100,
+		BreakdownContinue, Downtime{Start: 50, Duration: 50}))
+	res, err := pipe.Run(context.Background(), 300)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	stats := res.Object("facility").Stats
+	if stats["failures"] != 3 || stats["downtime"] != 150 {
+		t.Error("Failures and downtime, expected", 3, 150, "got", stats["failures"], stats["downtime"])
+	}
+	// Facility serves about a half of transacts, other transacts wait in queue
+	if facility.cntTransact > 160 {
+		t.Error("Served transacts, expected not more than", 160, "got", facility.cntTransact)
+	}
+}
+
+func TestBreakdown_StoragePolicy(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	storage := NewStorage("storage", 2)
+	pipe.AddObject(NewGenerator("gen", 1, 0, 0, 0, nil)).
+		AddObject(NewEnter("enter", storage, 1)).
+		AddObject(NewHole("hole"))
+	pipe.Append(NewBreakdownCalendar("breakdown", storage, 0,
+		BreakdownInterrupt, Downtime{Start: 5, Duration: 5}))
+	_, err := pipe.Run(context.Background(), 100)
+	if !errors.Is(err, ErrBreakdownPolicy) {
+		t.Error("Run error, expected", ErrBreakdownPolicy, "got", err)
+	}
+}
+
+func TestBreakdown_AvailabilityOfEarlyStop(t *testing.T) {
+	facility := NewFacility("facility", 10, 0)
+	pipe, _, hole := newBreakdownPipeline(BreakdownContinue, facility)
+	pipe.TerminateWhen(func(p *Pipeline) bool { return hole.cntTransact > 0 })
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// Server is down 6 of 10 simulated moments
+	if stats := res.Object("facility").Stats; res.ModelTime != 10 || stats["availability"] != 40 {
+		t.Error("Model time and availability, expected", 10, 40, "got", res.ModelTime, stats["availability"])
+	}
+}
+
+func TestBreakdown_Check(t *testing.T) {
+	tests := []struct {
+		policy   BreakdownPolicy
+		period   int
+		calendar []Downtime
+		expected error
+	}{
+		{BreakdownReroute, 0, []Downtime{{Start: 4, Duration: 6}}, ErrNoRerouteDst},
+		{BreakdownContinue, 0, []Downtime{{Start: 4, Duration: -1}}, ErrBreakdownCalendar},
+		{BreakdownContinue, 0, []Downtime{{Start: 4, Duration: 6}, {Start: 8, Duration: 1}}, ErrBreakdownCalendar},
+		{BreakdownContinue, 0, []Downtime{{Start: 8, Duration: 1}, {Start: 4, Duration: 1}}, ErrBreakdownCalendar},
+		{BreakdownContinue, 10, []Downtime{{Start: 4, Duration: 6}, {Start: 12, Duration: 3}}, ErrBreakdownCalendar},
+		{BreakdownContinue, 20, []Downtime{{Start: 4, Duration: 6}, {Start: 10, Duration: 3}}, nil},
+	}
+	for _, tt := range tests {
+		facility := NewFacility("facility", 10, 0)
+		pipe, breakdown, _ := newBreakdownPipeline(tt.policy, facility)
+		breakdown.Period = tt.period
+		breakdown.Calendar = tt.calendar
+		res, err := pipe.Run(context.Background(), 100)
+		if !errors.Is(err, tt.expected) {
+			t.Error("Run error, calendar", tt.calendar, "expected", tt.expected, "got", err)
+		}
+		if tt.expected != nil && res.ModelTime != 0 {
+			t.Error("Model time, expected", 0, "got", res.ModelTime)
+		}
+	}
+}
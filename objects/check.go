@@ -19,6 +19,8 @@ type Check struct {
 	falseObj IBaseObj
 	// Parameters of transact for checking
 	parameters []Parameter
+	// Condition of Check created by NewCheckExpr
+	expr *Expr
 	// For counting true result checking
 	cntTrue int
 	// For counting false result checking
@@ -54,6 +56,16 @@ func NewCheck(name string, hndl HandleCheckingFunc, falseObj IBaseObj, parameter
 	return obj
 }
 
+// start - resolve entities of condition expression, unknown entity stops
+// simulation with model error before the first event
+func (obj *Check) start() {
+	if obj.expr != nil {
+		if err := obj.expr.resolve(obj.Pipe); err != nil {
+			panic(err)
+		}
+	}
+}
+
 // AppendTransact append transact to object
 func (obj *Check) AppendTransact(transact *Transaction) bool {
 	obj.BaseObj.AppendTransact(transact)
@@ -80,3 +92,11 @@ func (obj *Check) Report() {
 	obj.BaseObj.Report()
 	fmt.Printf("Check result true %d\tCheck result false %d\n\n", obj.cntTrue, obj.cntFalse)
 }
+
+// Stats - get statistics of object
+func (obj *Check) Stats() map[string]float64 {
+	return map[string]float64{
+		"true":  float64(obj.cntTrue),
+		"false": float64(obj.cntFalse),
+	}
+}
@@ -46,5 +46,17 @@ func (obj *Count) AppendTransact(transact *Transaction) bool {
 
 // Report - print report about object
 func (obj *Count) Report() {
-	fmt.Printf("Count value %d\n", obj.value)
+	fmt.Printf("Count value %d\n", obj.GetValue())
+}
+
+// GetValue - get value of counter
+func (obj *Count) GetValue() int {
+	return *obj.value
+}
+
+// Stats - get statistics of object
+func (obj *Count) Stats() map[string]float64 {
+	return map[string]float64{
+		"value": float64(*obj.value),
+	}
 }
@@ -0,0 +1,149 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// Event is an entry of the future events chain. It describes the moment of
+// model time when object must handle transaction
+type Event struct {
+	Time     int          // Model time of event
+	Obj      IBaseObj     // Object which handle event
+	Transact *Transaction // Transaction for handling, nil for wake-up of object
+	priority int          // Priority of transaction at the moment of scheduling
+	seq      int          // Sequence number, keeps FIFO order for same time
+	index    int          // Index of event in heap, -1 if event not in chain
+}
+
+// IsScheduled - is event still in the future events chain?
+func (e *Event) IsScheduled() bool {
+	return e.index >= 0
+}
+
+// objID - get ID of object of event, -1 if event has not object
+func (e *Event) objID() int {
+	if e.Obj == nil {
+		return -1
+	}
+	return e.Obj.GetID()
+}
+
+// eventHeap implements heap.Interface
+type eventHeap []*Event
+
+// Len is part of sort.Interface.
+func (h eventHeap) Len() int {
+	return len(h)
+}
+
+// Less is part of sort.Interface. Events are ordered by time, then by priority
+// of transaction (higher first), then by object ID, then by time of scheduling.
+func (h eventHeap) Less(i, j int) bool {
+	if h[i].Time != h[j].Time {
+		return h[i].Time < h[j].Time
+	}
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	if idI, idJ := h[i].objID(), h[j].objID(); idI != idJ {
+		return idI < idJ
+	}
+	return h[i].seq < h[j].seq
+}
+
+// Swap is part of sort.Interface.
+func (h eventHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+// Push is part of heap.Interface.
+func (h *eventHeap) Push(x interface{}) {
+	e := x.(*Event)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+// Pop is part of heap.Interface.
+func (h *eventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
+
+// EventChain is a future events chain, a priority queue of events ordered by
+// model time. Events with same time are ordered by priority of transaction, by
+// object ID and then by time of scheduling (FIFO)
+type EventChain struct {
+	events eventHeap
+	seq    int
+	mu     *sync.Mutex
+}
+
+// NewEventChain create new EventChain
+func NewEventChain() *EventChain {
+	return &EventChain{
+		mu: &sync.Mutex{},
+	}
+}
+
+// Schedule - add event to chain
+func (ec *EventChain) Schedule(e *Event) {
+	defer ec.mu.Unlock()
+	ec.mu.Lock()
+	ec.seq++
+	e.seq = ec.seq
+	if e.Transact != nil {
+		e.priority = e.Transact.GetPriority()
+	}
+	heap.Push(&ec.events, e)
+}
+
+// Cancel - remove event from chain
+func (ec *EventChain) Cancel(e *Event) {
+	defer ec.mu.Unlock()
+	ec.mu.Lock()
+	if e == nil || e.index < 0 || e.index >= len(ec.events) || ec.events[e.index] != e {
+		return
+	}
+	heap.Remove(&ec.events, e.index)
+}
+
+// NextTime - return time of nearest event, false if chain is empty
+func (ec *EventChain) NextTime() (int, bool) {
+	defer ec.mu.Unlock()
+	ec.mu.Lock()
+	if len(ec.events) == 0 {
+		return 0, false
+	}
+	return ec.events[0].Time, true
+}
+
+// PopDue - return and remove from chain all events with time is less or
+// equal than selected time
+func (ec *EventChain) PopDue(time int) []*Event {
+	defer ec.mu.Unlock()
+	ec.mu.Lock()
+	var due []*Event
+	for len(ec.events) > 0 && ec.events[0].Time <= time {
+		due = append(due, heap.Pop(&ec.events).(*Event))
+	}
+	return due
+}
+
+// Len - return length of chain
+func (ec *EventChain) Len() int {
+	defer ec.mu.Unlock()
+	ec.mu.Lock()
+	return len(ec.events)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"testing"
+)
+
+func TestEventChain_PopDue(t *testing.T) {
+	ec := NewEventChain()
+	e1 := &Event{Time: 10, index: -1}
+	e2 := &Event{Time: 5, index: -1}
+	e3 := &Event{Time: 10, index: -1}
+	e4 := &Event{Time: 20, index: -1}
+	ec.Schedule(e1)
+	ec.Schedule(e2)
+	ec.Schedule(e3)
+	ec.Schedule(e4)
+	ec.Cancel(e4)
+	if e4.IsScheduled() {
+		t.Error("Event scheduled after cancel, expected", false, "got", e4.IsScheduled())
+	}
+	next, ok := ec.NextTime()
+	if !ok || next != 5 {
+		t.Error("Next time, expected", 5, "got", next)
+	}
+	due := ec.PopDue(10)
+	if len(due) != 3 {
+		t.Fatal("Due events, expected", 3, "got", len(due))
+	}
+	if due[0] != e2 || due[1] != e1 || due[2] != e3 {
+		t.Error("Due events order, expected", []*Event{e2, e1, e3}, "got", due)
+	}
+	if ec.Len() != 0 {
+		t.Error("Chain length, expected", 0, "got", ec.Len())
+	}
+}
+
+func TestPipeline_StartJumpsToEvents(t *testing.T) {
+	pipe := NewPipeline("pipe")
+	hole := NewHole("hole")
+	pipe.AddObject(NewGenerator("gen", 100, 0, 0, 0, nil)).
+		AddObject(NewAdvance("adv", 30, 0)).
+		AddObject(hole)
+	pipe.Start(1000)
+	<-pipe.Done
+	if hole.cntTransact != 9 {
+		t.Error("Killed transacts, expected", 9, "got", hole.cntTransact)
+	}
+	if hole.sumLife != 9*30 {
+		t.Error("Sum life, expected", 9*30, "got", hole.sumLife)
+	}
+	if pipe.ModelTime != 1000 {
+		t.Error("Model time, expected", 1000, "got", pipe.ModelTime)
+	}
+}
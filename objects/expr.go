@@ -0,0 +1,782 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+// Expr is a compiled expression of small embedded language for conditions of
+// Check and values of Assign, for example `P$size > 3 && Q$"Cook queue" < 5`.
+// Expressions are compiled once, syntax, SNA codes and functions are checked
+// at compile time, names of entities are resolved when simulation starts. The
+// language has:
+//   - numbers (int and float), strings in double quotes, true and false;
+//   - arithmetic + - * / %, comparisons == != < <= > >=, boolean && || !
+//     and parentheses. Arithmetic of two ints is int, except / which is float;
+//   - SNA as CODE$name or CODE$"name with spaces", for example P$size, Q$queue,
+//     X$revenue, FN$service, and AC1, C1, M1, PR, XN1 without name. Values of
+//     P and X are values of parameter and savevalue as is, whole values of
+//     another SNA are ints;
+//   - functions min, max, abs, round, rn(stream) and draws of distributions
+//     uniform(min, max), exponential(mean), normal(mean, stddev),
+//     triangular(min, mode, max), erlang(k, mean), lognormal(mu, sigma),
+//     poisson(mean). Draws use random stream RN1 of pipeline, another stream
+//     is selected by extra last argument, for example exponential(5, 2).
+//     uniform of ints is int, uniform of floats is float.
+type Expr struct {
+	Source string   // Source of expression
+	root   exprNode // Root of syntax tree
+}
+
+// exprNode is a node of syntax tree of expression
+type exprNode interface {
+	eval(transact *Transaction) (interface{}, error)
+}
+
+// CompileExpr compiles expression, returns error if expression is invalid
+func CompileExpr(src string) (*Expr, error) {
+	p := &exprParser{src: src}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("expression %q: %v", src, err)
+	}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q at %d", p.peek().text, p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %v", src, err)
+	}
+	return &Expr{Source: src, root: root}, nil
+}
+
+// MustCompileExpr compiles expression as CompileExpr, it panics if expression
+// is invalid
+func MustCompileExpr(src string) *Expr {
+	e, err := CompileExpr(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String - get source of expression
+func (e *Expr) String() string {
+	return e.Source
+}
+
+// Eval - evaluate expression for transact, result is int, float64, string or
+// bool
+func (e *Expr) Eval(transact *Transaction) (interface{}, error) {
+	v, err := e.root.eval(transact)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %v", e.Source, err)
+	}
+	return v, nil
+}
+
+// EvalBool - evaluate boolean expression for transact
+func (e *Expr) EvalBool(transact *Transaction) (bool, error) {
+	v, err := e.Eval(transact)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q: result %v is not boolean", e.Source, v)
+	}
+	return b, nil
+}
+
+// EvalFloat - evaluate numeric expression for transact
+func (e *Expr) EvalFloat(transact *Transaction) (float64, error) {
+	v, err := e.Eval(transact)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := toFloat64(v)
+	if !ok {
+		return 0, fmt.Errorf("expression %q: result %v is not a number", e.Source, v)
+	}
+	return f, nil
+}
+
+// Checking - get checking function of Check, error of evaluation stops
+// simulation with model error
+func (e *Expr) Checking() HandleCheckingFunc {
+	return func(obj *Check, transact *Transaction) bool {
+		b, err := e.EvalBool(transact)
+		if err != nil {
+			panic(err)
+		}
+		return b
+	}
+}
+
+// resolve - check that entities of SNA of expression are registered in
+// pipeline, returns ErrEntityNotFound if entity not found
+func (e *Expr) resolve(pipe *Pipeline) error {
+	if err := resolveNode(e.root, pipe); err != nil {
+		return fmt.Errorf("expression %q: %w", e.Source, err)
+	}
+	return nil
+}
+
+// resolveNode - check entities of SNA of node and its children
+func resolveNode(node exprNode, pipe *Pipeline) error {
+	switch n := node.(type) {
+	case *snaNode:
+		switch {
+		case n.code == "P" || !snaNeedsName(n.code):
+			return nil
+		case n.code == "FN":
+			if pipe.GetFunction(n.name) == nil {
+				return fmt.Errorf("FN$%s: %w", n.name, ErrEntityNotFound)
+			}
+			return nil
+		}
+		_, err := pipe.Attr(n.code, n.name)
+		return err
+	case *unaryNode:
+		return resolveNode(n.operand, pipe)
+	case *binaryNode:
+		if err := resolveNode(n.left, pipe); err != nil {
+			return err
+		}
+		return resolveNode(n.right, pipe)
+	case *callNode:
+		for _, a := range n.args {
+			if err := resolveNode(a, pipe); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NewCheckExpr creates new Check with condition as expression.
+// name - name of object; condition - boolean expression; falseObj -
+// destination of the Active Transaction in case false result of checking
+func NewCheckExpr(name, condition string, falseObj IBaseObj) (*Check, error) {
+	e, err := CompileExpr(condition)
+	if err != nil {
+		return nil, err
+	}
+	obj := NewCheck(name, e.Checking(), falseObj)
+	obj.expr = e
+	return obj, nil
+}
+
+// NewAssignExpr creates new Assign with values as expressions.
+// name - name of object; assignments - assignments "parameter = expression",
+// parameter may be written as P$parameter
+func NewAssignExpr(name string, assignments ...string) (*Assign, error) {
+	parameters := make([]Parameter, 0, len(assignments))
+	for _, a := range assignments {
+		idx := assignIndex(a)
+		if idx < 0 {
+			return nil, fmt.Errorf("assignment %q: '=' not found", a)
+		}
+		param := strings.TrimPrefix(strings.TrimSpace(a[:idx]), "P$")
+		if param == "" {
+			return nil, fmt.Errorf("assignment %q: name of parameter is empty", a)
+		}
+		if uq, err := strconv.Unquote(param); err == nil {
+			param = uq
+		}
+		e, err := CompileExpr(strings.TrimSpace(a[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("assignment %q: %v", a, err)
+		}
+		parameters = append(parameters, Parameter{Name: param, Value: e})
+	}
+	return NewAssign(name, parameters...), nil
+}
+
+// assignIndex - get index of '=' of assignment, which is not a part of
+// comparison, -1 if not found
+func assignIndex(a string) int {
+	for i := 0; i < len(a); i++ {
+		if a[i] != '=' {
+			continue
+		}
+		if (i > 0 && strings.ContainsRune("=!<>", rune(a[i-1]))) || (i+1 < len(a) && a[i+1] == '=') {
+			return -1
+		}
+		return i
+	}
+	return -1
+}
+
+// Token kinds of expression
+const (
+	tokEOF = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// exprParser is a recursive descent parser of expression
+type exprParser struct {
+	src    string
+	tokens []exprToken
+	idx    int
+}
+
+// tokenize - split source to tokens
+func (p *exprParser) tokenize() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			return fmt.Errorf("invalid UTF-8 at %d", i)
+		case unicode.IsSpace(c):
+			i += size
+		case isDigit(src[i]) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.' ||
+				src[j] == 'e' || src[j] == 'E' ||
+				((src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E'))) {
+				j++
+			}
+			p.tokens = append(p.tokens, exprToken{tokNumber, src[i:j], i})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return fmt.Errorf("unterminated string at %d", i)
+			}
+			s, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return fmt.Errorf("invalid string at %d", i)
+			}
+			p.tokens = append(p.tokens, exprToken{tokString, s, i})
+			i = j + 1
+		case unicode.IsLetter(c) || c == '_':
+			j := i + size
+			for j < len(src) {
+				r, n := utf8.DecodeRuneInString(src[j:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				j += n
+			}
+			p.tokens = append(p.tokens, exprToken{tokIdent, src[i:j], i})
+			i = j
+		default:
+			op := ""
+			for _, o := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", ",", "$"} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				if c == '=' {
+					return fmt.Errorf("unexpected '=' at %d, use '==' for comparison", i)
+				}
+				return fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			p.tokens = append(p.tokens, exprToken{tokOp, op, i})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, exprToken{tokEOF, "end of expression", len(src)})
+	return nil
+}
+
+// isDigit - check that byte is ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// peek - get current token
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.idx]
+}
+
+// next - get current token and move to next token
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.idx]
+	if t.kind != tokEOF {
+		p.idx++
+	}
+	return t
+}
+
+// accept - move to next token if current token is operator op
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.idx++
+		return true
+	}
+	return false
+}
+
+// expect - move to next token if current token is operator op, otherwise
+// returns error
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q at %d, got %q", op, p.peek().pos, p.peek().text)
+	}
+	return nil
+}
+
+// parseBinary - parse left-associative binary operators of one precedence
+func (p *exprParser) parseBinary(operand func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range ops {
+			if p.accept(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseAdditive, "==", "!=", "<=", ">=", "<", ">")
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: op, operand: operand}, nil
+		}
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		if v, err := strconv.Atoi(t.text); err == nil {
+			return &constNode{v}, nil
+		}
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return &constNode{v}, nil
+	case tokString:
+		return &constNode{t.text}, nil
+	case tokIdent:
+		return p.parseIdent(t)
+	case tokOp:
+		if t.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// parseIdent - parse constant, SNA or call of function
+func (p *exprParser) parseIdent(t exprToken) (exprNode, error) {
+	switch {
+	case t.text == "true" || t.text == "false":
+		return &constNode{t.text == "true"}, nil
+	case p.accept("$"):
+		n := p.next()
+		if n.kind != tokIdent && n.kind != tokString && n.kind != tokNumber {
+			return nil, fmt.Errorf("expected name after %s$ at %d", t.text, n.pos)
+		}
+		if t.text != "P" && t.text != "FN" && !isSNA(t.text) {
+			return nil, fmt.Errorf("unknown SNA %q at %d", t.text, t.pos)
+		}
+		return &snaNode{code: t.text, name: n.text}, nil
+	case p.accept("("):
+		arity, ok := exprFuncArity[t.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q at %d", t.text, t.pos)
+		}
+		call := &callNode{name: t.text}
+		for !p.accept(")") {
+			if len(call.args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+		}
+		if len(call.args) != arity && (!exprFuncDraws[t.text] || len(call.args) != arity+1) {
+			if exprFuncDraws[t.text] {
+				return nil, fmt.Errorf("function %s at %d expects %d arguments and optional stream, got %d",
+					t.text, t.pos, arity, len(call.args))
+			}
+			return nil, fmt.Errorf("function %s at %d expects %d arguments, got %d", t.text, t.pos, arity, len(call.args))
+		}
+		return call, nil
+	case isSNA(t.text) && !snaNeedsName(t.text):
+		return &snaNode{code: t.text}, nil
+	}
+	return nil, fmt.Errorf("unknown name %q at %d", t.text, t.pos)
+}
+
+// constNode is a constant
+type constNode struct {
+	value interface{}
+}
+
+func (n *constNode) eval(transact *Transaction) (interface{}, error) {
+	return n.value, nil
+}
+
+// snaNode is a SNA of transact or entity
+type snaNode struct {
+	code, name string
+}
+
+func (n *snaNode) eval(transact *Transaction) (interface{}, error) {
+	if transact == nil {
+		return nil, fmt.Errorf("%s$%s: transact is nil", n.code, n.name)
+	}
+	switch n.code {
+	case "P":
+		v := transact.GetParameter(n.name)
+		if v == nil {
+			return nil, fmt.Errorf("P$%s: parameter is not set", n.name)
+		}
+		return v, nil
+	case "FN":
+		var f *Function
+		if pipe := transact.GetPipeline(); pipe != nil {
+			f = pipe.GetFunction(n.name)
+		}
+		if f == nil {
+			return nil, fmt.Errorf("FN$%s: %w", n.name, ErrEntityNotFound)
+		}
+		return f.Evaluate(transact), nil
+	case "X":
+		var sv *SaveValue
+		if pipe := transact.GetPipeline(); pipe != nil {
+			sv = pipe.GetSaveValue(n.name)
+		}
+		if sv == nil {
+			return nil, fmt.Errorf("X$%s: %w", n.name, ErrEntityNotFound)
+		}
+		return sv.Get(), nil
+	}
+	v, err := transact.Attr(n.code, n.name)
+	if err != nil {
+		return nil, err
+	}
+	// Counts, clock, IDs and flags are ints
+	return wholeToInt(v), nil
+}
+
+// unaryNode is a unary operator
+type unaryNode struct {
+	op      string
+	operand exprNode
+}
+
+func (n *unaryNode) eval(transact *Transaction) (interface{}, error) {
+	v, err := n.operand.eval(transact)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case bool:
+		if n.op == "!" {
+			return !x, nil
+		}
+	case int:
+		if n.op == "-" {
+			return -x, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -x, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid operand %v of %s", v, n.op)
+}
+
+// binaryNode is a binary operator
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *binaryNode) eval(transact *Transaction) (interface{}, error) {
+	l, err := n.left.eval(transact)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand %v of %s", l, n.op)
+		}
+		if lb == (n.op == "||") {
+			// Short-circuit evaluation
+			return lb, nil
+		}
+		r, err := n.right.eval(transact)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand %v of %s", r, n.op)
+		}
+		return rb, nil
+	}
+	r, err := n.right.eval(transact)
+	if err != nil {
+		return nil, err
+	}
+	if li, ok := l.(int); ok {
+		if ri, ok := r.(int); ok && n.op != "/" {
+			return intOp(n.op, li, ri)
+		}
+	}
+	lf, lok := toFloat64(l)
+	rf, rok := toFloat64(r)
+	if lok && rok {
+		return floatOp(n.op, lf, rf)
+	}
+	switch n.op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	}
+	ls, lok := l.(string)
+	rs, rok := r.(string)
+	if lok && rok {
+		switch n.op {
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		case "+":
+			return ls + rs, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid operands %v and %v of %s", l, r, n.op)
+}
+
+// intOp - apply operator to ints
+func intOp(op string, l, r int) (interface{}, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l % r, nil
+	}
+	return floatOp(op, float64(l), float64(r))
+}
+
+// floatOp - apply operator to numbers
+func floatOp(op string, l, r float64) (interface{}, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/", "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		if op == "%" {
+			return math.Mod(l, r), nil
+		}
+		return l / r, nil
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	}
+	return nil, fmt.Errorf("invalid operands %v and %v of %s", l, r, op)
+}
+
+// exprFuncArity - number of arguments of functions of expression
+var exprFuncArity = map[string]int{
+	"min":         2,
+	"max":         2,
+	"abs":         1,
+	"round":       1,
+	"rn":          1,
+	"uniform":     2,
+	"exponential": 1,
+	"normal":      2,
+	"triangular":  3,
+	"erlang":      2,
+	"lognormal":   2,
+	"poisson":     1,
+}
+
+// exprFuncDraws - functions of expression, which draw values of distributions,
+// extra last argument of them is a number of random stream
+var exprFuncDraws = map[string]bool{
+	"uniform":     true,
+	"exponential": true,
+	"normal":      true,
+	"triangular":  true,
+	"erlang":      true,
+	"lognormal":   true,
+	"poisson":     true,
+}
+
+// callNode is a call of function
+type callNode struct {
+	name string
+	args []exprNode
+}
+
+func (n *callNode) eval(transact *Transaction) (interface{}, error) {
+	args := make([]float64, len(n.args))
+	ints := true // Arguments of function are ints
+	for i, a := range n.args {
+		v, err := a.eval(transact)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, fmt.Errorf("argument %v of %s is not a number", v, n.name)
+		}
+		if _, ok := v.(int); !ok && i < exprFuncArity[n.name] {
+			ints = false
+		}
+		args[i] = f
+	}
+	switch n.name {
+	case "min":
+		return math.Min(args[0], args[1]), nil
+	case "max":
+		return math.Max(args[0], args[1]), nil
+	case "abs":
+		return math.Abs(args[0]), nil
+	case "round":
+		return int(math.Round(args[0])), nil
+	}
+	if transact == nil || transact.GetPipeline() == nil {
+		return nil, fmt.Errorf("%s: transact has not pipeline", n.name)
+	}
+	pipe := transact.GetPipeline()
+	if n.name == "rn" {
+		return pipe.RN(int(args[0])).Float64(), nil
+	}
+	stream := 1
+	if len(args) > exprFuncArity[n.name] {
+		last := args[len(args)-1]
+		if last != math.Trunc(last) || last < 1 {
+			return nil, fmt.Errorf("%s: stream %v is not a positive int", n.name, last)
+		}
+		stream = int(last)
+		args = args[:len(args)-1]
+	}
+	r := pipe.RN(stream)
+	var d distributions.Distribution
+	switch n.name {
+	case "uniform":
+		if ints {
+			return distributions.Int(distributions.NewUniform(int(args[0]), int(args[1])), r), nil
+		}
+		if args[1] <= args[0] {
+			return args[0], nil
+		}
+		return args[0] + r.Float64()*(args[1]-args[0]), nil
+	case "exponential":
+		d = distributions.NewExponential(args[0])
+	case "normal":
+		d = distributions.NewNormal(args[0], args[1])
+	case "triangular":
+		d = distributions.NewTriangular(args[0], args[1], args[2])
+	case "erlang":
+		d = distributions.NewErlang(int(args[0]), args[1])
+	case "lognormal":
+		d = distributions.NewLogNormal(args[0], args[1])
+	case "poisson":
+		return distributions.Int(distributions.NewPoisson(args[0]), r), nil
+	}
+	return d.Sample(r), nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("Cook queue")
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(queue, facility)
+	pipe.Append(facility)
+	pipe.AddSaveValue(NewSaveValue("revenue", 2.5))
+	facility.AppendTransact(NewTransaction(pipe))
+	queue.AppendTransact(NewTransaction(pipe))
+	transact := NewTransaction(pipe)
+	transact.SetParameters([]Parameter{{Name: "size", Value: 4}, {Name: "class", Value: "VIP"}})
+	tests := []struct {
+		src      string
+		expected interface{}
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3 - -1", 10},
+		{"7 / 2", 3.5},
+		{"7 % 4 + 0.5", 3.5},
+		{`P$size > 3 && Q$"Cook queue" < 5`, true},
+		{`P$class == "VIP" || P$missing > 0`, true},
+		{`!(P$size >= 4)`, false},
+		{"X$revenue * P$size", 10.0},
+		{"F$facility + AC1", 1},
+		{"QA$\"Cook queue\" > 0", false},
+		{"max(P$size, 6) + round(2.6)", 9.0},
+		{`"a" + "b" == "ab"`, true},
+		{"uniform(2, 2)", 2},
+	}
+	for _, tt := range tests {
+		e, err := CompileExpr(tt.src)
+		if err != nil {
+			t.Error("Compile", tt.src, "expected", nil, "got", err)
+			continue
+		}
+		v, err := e.Eval(transact)
+		if err != nil || v != tt.expected {
+			t.Errorf("Eval %s, expected %v (%T), got %v (%T) %v", tt.src, tt.expected, tt.expected, v, v, err)
+		}
+	}
+	for _, src := range []string{"P$missing > 0", "P$class * 2", "1 / 0", `1 < "a"`} {
+		if _, err := MustCompileExpr(src).Eval(transact); err == nil {
+			t.Error("Eval", src, "expected error, got", err)
+		}
+	}
+}
+
+func TestCompileExpr_Errors(t *testing.T) {
+	for _, src := range []string{"", "1 +", "P$size = 3", "(1 + 2", "ZZ$x > 1", "foo(1)",
+		"max(1)", `"abc`, "Q > 1", "1 2"} {
+		if _, err := CompileExpr(src); err == nil {
+			t.Error("Compile", src, "expected error, got", err)
+		}
+	}
+}
+
+func TestCheckAndAssignExpr(t *testing.T) {
+	if _, err := NewCheckExpr("check", "P$size >", nil); err == nil {
+		t.Error("Check with invalid expression, expected error, got", err)
+	}
+	if _, err := NewAssignExpr("assign", "size + 1"); err == nil {
+		t.Error("Assign without '=', expected error, got", err)
+	}
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 6, nil)
+	gen.Parameters = []ParameterTemplate{SampledIntParameter("size", distributions.NewUniform(1, 3))}
+	check, err := NewCheckExpr("check", "P$size >= 2 && XN1 > 3", NewHole("small"))
+	if err != nil {
+		t.Fatal("Check, expected", nil, "got", err)
+	}
+	big := NewHole("big")
+	pipe.Append(gen, check)
+	pipe.Append(check, big)
+	pipe.Append(big)
+	var expected int
+	checkExpected := NewCheck("count", func(obj *Check, transact *Transaction) bool {
+		if transact.GetIntParameter("size") >= 2 && transact.GetID() > 3 {
+			expected++
+		}
+		return true
+	}, nil)
+	gen.SetDst(checkExpected)
+	pipe.Append(checkExpected, check)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if check.cntTrue != expected || check.cntTrue+check.cntFalse != 6 {
+		t.Error("Check results, expected", expected, 6-expected, "got", check.cntTrue, check.cntFalse)
+	}
+	assign, err := NewAssignExpr("assign", "size = XN1 % 3", `P$"double size" = P$size * 2.5`)
+	if err != nil {
+		t.Fatal("Assign, expected", nil, "got", err)
+	}
+	pipe.Append(assign, big)
+	transact := NewTransaction(pipe)
+	transact.SetParameters([]Parameter{{Name: "size", Value: 2}})
+	assign.AppendTransact(transact)
+	// Assignments are applied in order, double size is computed from new size
+	if transact.GetParameter("double size") != 2.5*float64(transact.GetID()%3) || transact.GetParameter("size") != transact.GetID()%3 {
+		t.Error("Assigned parameters, expected", 2.5*float64(transact.GetID()%3), transact.GetID()%3, "got", transact.GetParameters())
+	}
+}
+
+func TestExpr_NonASCII(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("Очередь")
+	pipe.Append(queue)
+	queue.AppendTransact(NewTransaction(pipe))
+	transact := NewTransaction(pipe)
+	transact.SetParameters([]Parameter{{Name: "размер", Value: 4}})
+	v, err := MustCompileExpr("Q$Очередь + P$размер").Eval(transact)
+	if err != nil || v != 5 {
+		t.Error("Eval non-ASCII names, expected", 5, "got", v, err)
+	}
+	if _, err := CompileExpr("P$size \xff 1"); err == nil {
+		t.Error("Compile invalid UTF-8, expected error, got", err)
+	}
+}
+
+func TestExpr_Draws(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	transact := NewTransaction(pipe)
+	for i := 0; i < 100; i++ {
+		v, err := MustCompileExpr("uniform(1.5, 2.5)").Eval(transact)
+		if f, ok := v.(float64); err != nil || !ok || f < 1.5 || f > 2.5 {
+			t.Fatal("Float uniform, expected float64 between", 1.5, 2.5, "got", v, err)
+		}
+	}
+	v, err := MustCompileExpr("exponential(5, 2)").Eval(transact)
+	expected := distributions.NewExponential(5).Sample(NewPipelineWithSeed("pipe", 1).RN(2))
+	if err != nil || v != expected {
+		t.Error("Draw from stream 2, expected", expected, "got", v, err)
+	}
+	v, err = MustCompileExpr("uniform(1, 10, 3)").Eval(transact)
+	expectedInt := distributions.Int(distributions.NewUniform(1, 10), NewPipelineWithSeed("pipe", 1).RN(3))
+	if err != nil || v != expectedInt {
+		t.Error("Int uniform from stream 3, expected", expectedInt, "got", v, err)
+	}
+	if _, err := CompileExpr("exponential(5, 2, 3)"); err == nil {
+		t.Error("Compile extra arguments, expected error, got", err)
+	}
+	if _, err := MustCompileExpr("normal(5, 1, 0.5)").Eval(transact); err == nil {
+		t.Error("Eval invalid stream, expected error, got", err)
+	}
+}
+
+func TestCheckExpr_UnknownEntity(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 0, nil)
+	check, err := NewCheckExpr("check", `Q$"Cook queue" < 5`, nil)
+	if err != nil {
+		t.Fatal("Check, expected", nil, "got", err)
+	}
+	hole := NewHole("hole")
+	pipe.Append(gen, check)
+	pipe.Append(check, hole)
+	pipe.Append(hole)
+	res, err := pipe.Run(context.Background(), 100)
+	if !errors.Is(err, ErrEntityNotFound) {
+		t.Fatal("Run error, expected", ErrEntityNotFound, "got", err)
+	}
+	if res.ModelTime != 0 || check.cntTrue+check.cntFalse != 0 {
+		t.Error("Model time and checks, expected", 0, 0, "got", res.ModelTime, check.cntTrue+check.cntFalse)
+	}
+	pipe = NewPipelineWithSeed("pipe", 1)
+	gen = NewGenerator("gen", 10, 0, 0, 0, nil)
+	assign, err := NewAssignExpr("assign", "price = X$price * 2")
+	if err != nil {
+		t.Fatal("Assign, expected", nil, "got", err)
+	}
+	hole = NewHole("hole")
+	pipe.Append(gen, assign)
+	pipe.Append(assign, hole)
+	pipe.Append(hole)
+	if _, err := pipe.Run(context.Background(), 100); !errors.Is(err, ErrEntityNotFound) {
+		t.Error("Run error, expected", ErrEntityNotFound, "got", err)
+	}
+}
@@ -6,8 +6,10 @@ package objects
 
 import (
 	"fmt"
+	"math"
 	"sync"
 
+	"github.com/soldatov-s/go-gpss/distributions"
 	utils "github.com/soldatov-s/go-gpss/internal"
 )
 
@@ -16,13 +18,35 @@ type IFacility interface {
 	IsEmpty() bool
 }
 
+// HandleFacilityFunc is a function signature for generate advance of transact
+// in facility
+type HandleFacilityFunc func(obj *Facility, transact *Transaction) int
+
+// FacilityTime - default function for generate advance of transact in
+// facility, it is Interval ± Modificator or value of Distribution. Function of
+// pipeline used as Distribution is evaluated for transact.
+func FacilityTime(obj *Facility, transact *Transaction) int {
+	if f, ok := obj.Distribution.(*Function); ok {
+		return int(math.Round(f.Evaluate(transact)))
+	}
+	return obj.GenerateAdvance()
+}
+
 // Facility entity with advance in it
 type Facility struct {
 	BaseObj
+	availability
 	// The mean time increment
 	Interval int
 	// The time half-range
 	Modificator int
+	// Distribution of time increment, it is used instead of Interval and
+	// Modificator if it is set
+	Distribution distributions.Distribution
+	// Number of random stream, RN1 by default
+	Stream int
+	// Function for generate advance of transact
+	HandleAdvance HandleFacilityFunc
 	// Holded transast ID
 	HoldedTransactID int
 	// For backuping Facility/Bifacility name if we includes Facility in Bifacility
@@ -31,6 +55,19 @@ type Facility struct {
 	sumAdvance float64
 	// For counting the transacts that go through Bifacility
 	cntTransact float64
+	// Preemptive mode, transact with higher priority interrupts holded transact
+	Preemptive bool
+	// Destination of interrupted transacts, if nil interrupted transact
+	// waits and resumes after the preemptor leaves facility
+	PreemptDst IBaseObj
+	// Interrupted transacts, waiting for resume
+	suspended *TransactTable
+	// For counting the preemptions
+	cntPreempt float64
+	// Scheduled end of advance of holded transact
+	event *Event
+	// State of facility, 1 if busy, weighted by time
+	busy timeWeighted
 }
 
 // NewFacility creates new Facility.
@@ -41,22 +78,27 @@ func NewFacility(name string, interval, modificator int) *Facility {
 	obj.BaseObj.Init(name)
 	obj.Interval = interval
 	obj.Modificator = modificator
+	obj.HandleAdvance = FacilityTime
 	obj.HoldedTransactID = -1
+	obj.suspended = NewTransactTable()
 	return obj
 }
 
 // GenerateAdvance generate advance for facility
 func (obj *Facility) GenerateAdvance() int {
+	if obj.Distribution != nil {
+		return distributions.Int(obj.Distribution, obj.Pipe.RN(obj.Stream))
+	}
 	advance := obj.Interval
 	if obj.Modificator > 0 {
-		advance += utils.GetRandom(-obj.Modificator, obj.Modificator)
+		advance += utils.GetRandomFrom(obj.Pipe.RN(obj.Stream), -obj.Modificator, obj.Modificator)
 	}
 	return advance
 }
 
-// HandleTransact handle transact
-func (obj *Facility) HandleTransact(transact *Transaction) {
-	transact.DecTiсks()
+// HandleTransact handle transact, transact leaves facility if the advance is over.
+// Returns true if transact leaved facility.
+func (obj *Facility) HandleTransact(transact *Transaction) bool {
 	transact.PrintInfo()
 	if transact.IsTheEnd() {
 		if obj.bakupFacilityName != "" {
@@ -69,55 +111,169 @@ func (obj *Facility) HandleTransact(transact *Transaction) {
 			if v.AppendTransact(transact) {
 				obj.tb.Remove(transact)
 				obj.HoldedTransactID = -1
-				return
+				obj.busy.set(obj.Pipe.ModelTime, 0)
+				obj.resume()
+				return true
 			}
 		}
 		transact.SetParameter("Facility", obj.name)
 	}
+	return false
 }
 
-// HandleTransacts handle transacts in goroutine
-func (obj *Facility) HandleTransacts(wg *sync.WaitGroup) {
-	if obj.tb.Len() == 0 {
-		wg.Done()
+// HandleEvent handle the end of advance of transact
+func (obj *Facility) HandleEvent(e *Event) {
+	if obj.event != e {
+		// Transact already left facility
 		return
 	}
-	go func() {
-		defer wg.Done()
-		transacts := obj.tb.Items()
-		for _, tr := range transacts {
-			obj.HandleTransact(tr.transact)
+	e.Transact.ResetTicks()
+	obj.HandleTransact(e.Transact)
+}
+
+// GetBlocked - get transacts which advance is over, but destination was busy
+func (obj *Facility) GetBlocked() []*Transaction {
+	var blocked []*Transaction
+	for _, tr := range obj.tb.List() {
+		if tr.transact.IsTheEnd() {
+			blocked = append(blocked, tr.transact)
 		}
-	}()
+	}
+	return blocked
 }
 
-// AppendTransact append transact to object
+// Release - try to send blocked transact to destination
+func (obj *Facility) Release(transact *Transaction) bool {
+	if obj.tb.Item(transact.GetID()) == nil || !transact.IsTheEnd() {
+		return false
+	}
+	return obj.HandleTransact(transact)
+}
+
+// HandleTransacts handle transacts, tries to move blocked transacts
+func (obj *Facility) HandleTransacts(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for _, transact := range obj.GetBlocked() {
+		obj.Release(transact)
+	}
+}
+
+// AppendTransact append transact to object. In preemptive mode transact with
+// higher priority interrupts holded transact. Unavailable facility refuses
+// all transacts.
 func (obj *Facility) AppendTransact(transact *Transaction) bool {
-	if obj.tb.Len() != 0 {
+	if !obj.IsAvailable() {
+		return false
+	}
+	if obj.tb.Len() != 0 && !obj.preempt(transact) {
 		// Facility is busy
 		return false
 	}
 	obj.BaseObj.AppendTransact(transact)
-	transact.SetHolder(obj.name)
-	advance := obj.GenerateAdvance()
+	advance := obj.HandleAdvance(obj, transact)
+	if advance < 0 {
+		advance = 0
+	}
 	obj.sumAdvance += float64(advance)
+	obj.cntTransact++
+	obj.hold(transact, advance)
+	return true
+}
+
+// hold - take ownership of facility by transact for advance
+func (obj *Facility) hold(transact *Transaction, advance int) {
+	transact.SetHolder(obj.name)
 	transact.SetTiсks(advance)
-	if transact.GetParameter("Facility") != nil {
-		obj.bakupFacilityName = transact.GetParameter("Facility").(string)
-	}
+	obj.bakupFacilityName = GetOr(transact, "Facility", "")
 	transact.SetParameter("Facility", obj.name)
 	obj.HoldedTransactID = transact.GetID()
 	obj.tb.Push(transact)
-	obj.cntTransact++
+	obj.busy.set(obj.Pipe.ModelTime, 1)
+	obj.event = obj.Pipe.Schedule(obj, transact, advance)
+}
+
+// preempt - interrupt holded transact, if facility is preemptive and transact
+// has higher priority than holded transact. Returns true if facility was
+// released.
+func (obj *Facility) preempt(transact *Transaction) bool {
+	item := obj.tb.Item(obj.HoldedTransactID)
+	if !obj.Preemptive || item == nil || item.transact.IsTheEnd() ||
+		transact.GetPriority() <= item.transact.GetPriority() {
+		return false
+	}
+	obj.cntPreempt++
+	obj.interrupt(obj.PreemptDst)
 	return true
 }
 
+// interrupt - interrupt holded transact and send it to destination, if
+// destination is nil or refuses it, transact waits for resume. Remaining ticks
+// of interrupted transact are saved in it.
+func (obj *Facility) interrupt(dst IBaseObj) {
+	holded := obj.tb.Item(obj.HoldedTransactID).transact
+	obj.Pipe.Cancel(obj.event)
+	remaining := obj.event.Time - obj.Pipe.ModelTime
+	holded.SetParameter("ticks", remaining)
+	holded.SetParameter("advance", holded.GetAdvanceTime()-remaining)
+	if obj.bakupFacilityName != "" {
+		holded.SetParameter("Facility", obj.bakupFacilityName)
+	} else {
+		holded.SetParameter("Facility", nil)
+	}
+	obj.tb.Remove(holded)
+	obj.HoldedTransactID = -1
+	obj.busy.set(obj.Pipe.ModelTime, 0)
+	utils.Log.Trace.Println("Interrupt transact ", holded.GetID(), " in ", obj.name)
+	if dst != nil && dst.AppendTransact(holded) {
+		obj.sumAdvance -= float64(remaining)
+		return
+	}
+	obj.suspended.PushByPriority(holded)
+}
+
+// resume - interrupted transact with highest priority takes ownership of
+// available facility for remaining ticks
+func (obj *Facility) resume() {
+	if !obj.IsAvailable() {
+		return
+	}
+	transact := obj.suspended.Pop()
+	if transact == nil {
+		return
+	}
+	utils.Log.Trace.Println("Resume transact ", transact.GetID(), " in ", obj.name)
+	obj.hold(transact, transact.GetTicks())
+}
+
+// SetUnavailable - facility becomes unavailable, holded transact is
+// interrupted or rerouted to destination according to policy
+func (obj *Facility) SetUnavailable(policy BreakdownPolicy, dst IBaseObj) {
+	if !obj.fail(obj.Pipe.ModelTime) || policy == BreakdownContinue {
+		return
+	}
+	item := obj.tb.Item(obj.HoldedTransactID)
+	if item == nil || item.transact.IsTheEnd() {
+		return
+	}
+	if policy != BreakdownReroute {
+		dst = nil
+	}
+	obj.interrupt(dst)
+}
+
+// SetAvailable - facility becomes available, interrupted transact resumes
+func (obj *Facility) SetAvailable() {
+	if obj.repair(obj.Pipe.ModelTime) && obj.tb.Len() == 0 {
+		obj.resume()
+	}
+}
+
 // Report - print report about object
 func (obj *Facility) Report() {
 	obj.BaseObj.Report()
-	avr := obj.sumAdvance / obj.cntTransact
-	fmt.Printf("Average advance %.2f \tAverage utilization %.2f%%\tNumber entries %.2f \t", avr,
-		100*avr*obj.cntTransact/float64(obj.Pipe.SimTime), obj.cntTransact)
+	fmt.Printf("Average advance %.2f \tAverage time/trans %.2f\tAverage utilization %.2f%%\tNumber entries %.2f \t",
+		obj.sumAdvance/obj.cntTransact, ratio(obj.busy.area(obj.Pipe.ModelTime), obj.cntTransact),
+		100*obj.busy.average(obj.Pipe.ModelTime), obj.cntTransact)
 	if obj.HoldedTransactID > 0 {
 		fmt.Print("Transact ", obj.HoldedTransactID, " in facility")
 		part, _, parentID := obj.tb.Item(obj.HoldedTransactID).transact.GetParts()
@@ -127,6 +283,10 @@ func (obj *Facility) Report() {
 	} else {
 		fmt.Print("Facility is empty")
 	}
+	if obj.Preemptive {
+		fmt.Printf("\tPreemptions %.2f\tSuspended %d", obj.cntPreempt, obj.suspended.Len())
+	}
+	obj.reportAvailability(obj.Pipe.ModelTime)
 	fmt.Printf("\n\n")
 }
 
@@ -134,3 +294,16 @@ func (obj *Facility) Report() {
 func (obj *Facility) IsEmpty() bool {
 	return obj.tb.Len() == 0
 }
+
+// Stats - get statistics of object
+func (obj *Facility) Stats() map[string]float64 {
+	return obj.statsAvailability(map[string]float64{
+		"average_advance": ratio(obj.sumAdvance, obj.cntTransact),
+		"average_time":    ratio(obj.busy.area(obj.Pipe.ModelTime), obj.cntTransact),
+		"utilization":     100 * obj.busy.average(obj.Pipe.ModelTime),
+		"entries":         obj.cntTransact,
+		"current_content": float64(obj.tb.Len()),
+		"preemptions":     obj.cntPreempt,
+		"suspended":       float64(obj.suspended.Len()),
+	}, obj.Pipe.ModelTime)
+}
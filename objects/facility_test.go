@@ -0,0 +1,107 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+)
+
+func newPreemptionPipeline(facility ...IBaseObj) (*Pipeline, *Hole) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	hole := NewHole("hole")
+	low := NewGenerator("low", 0, 0, 0, 1, nil)
+	high := NewGenerator("high", 4, 0, 0, 1, nil)
+	high.Priority = 5
+	pipe.AddObject(low, high)
+	for _, obj := range facility {
+		pipe.AddObject(obj)
+	}
+	pipe.AddObject(hole)
+	return pipe, hole
+}
+
+func TestFacility_Preempt(t *testing.T) {
+	facility := NewFacility("facility", 10, 0)
+	facility.Preemptive = true
+	pipe, hole := newPreemptionPipeline(facility)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if facility.cntPreempt != 1 {
+		t.Error("Preemptions, expected", 1, "got", facility.cntPreempt)
+	}
+	// High priority transact lives 10 ticks, low priority transact 20 ticks
+	if hole.cntTransact != 2 || hole.sumLife != 30 {
+		t.Error("Sum life, expected", 30, "got", hole.sumLife)
+	}
+	// Time of waiting for resume is not included in advance
+	if hole.sumAdvance != 20 {
+		t.Error("Sum advance, expected", 20, "got", hole.sumAdvance)
+	}
+}
+
+func TestFacility_PreemptDst(t *testing.T) {
+	facility := NewFacility("facility", 10, 0)
+	facility.Preemptive = true
+	removed := NewHole("removed")
+	facility.PreemptDst = removed
+	pipe, hole := newPreemptionPipeline(facility)
+	pipe.Append(removed)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if removed.cntTransact != 1 || removed.sumLife != 4 {
+		t.Error("Life of removed transact, expected", 4, "got", removed.sumLife)
+	}
+	if hole.cntTransact != 1 || hole.sumLife != 10 {
+		t.Error("Life of preemptor, expected", 10, "got", hole.sumLife)
+	}
+}
+
+func TestInFacility_Preempt(t *testing.T) {
+	in, out := NewBifacility("facility")
+	in.Preemptive = true
+	pipe, hole := newPreemptionPipeline(in, NewAdvance("service", 10, 0), out)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if in.cntPreempt != 1 {
+		t.Error("Preemptions, expected", 1, "got", in.cntPreempt)
+	}
+	if hole.cntTransact != 2 || hole.sumLife != 30 {
+		t.Error("Sum life, expected", 30, "got", hole.sumLife)
+	}
+	if in.sumAdvance != 20 {
+		t.Error("Sum advance, expected", 20, "got", in.sumAdvance)
+	}
+}
+
+func TestFacility_HandleAdvance(t *testing.T) {
+	bySize := func(transact *Transaction) int {
+		return 2 * transact.GetIntParameter("size")
+	}
+	facility := NewFacility("facility", 100, 0)
+	facility.HandleAdvance = func(obj *Facility, transact *Transaction) int { return bySize(transact) }
+	adv := NewAdvance("advance", 100, 0)
+	adv.HandleAdvance = func(obj *Advance, transact *Transaction) int { return bySize(transact) }
+	in, out := NewBifacility("bifacility")
+	in.HandleAdvance = func(obj *InFacility, transact *Transaction) int { return bySize(transact) }
+	pipe := NewPipelineWithSeed("pipe", 1)
+	hole := NewHole("hole")
+	pipe.AddObject(facility).AddObject(adv).AddObject(in).AddObject(out).AddObject(hole)
+	transact := NewTransaction(pipe)
+	transact.SetParameter("size", 3)
+	facility.AppendTransact(transact)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if hole.cntTransact != 1 || hole.sumLife != 3*6 {
+		t.Error("Sum life, expected", 3*6, "got", hole.sumLife)
+	}
+	if in.sumAdvance != 6 {
+		t.Error("Sum advance in bifacility, expected", 6, "got", in.sumAdvance)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+	utils "github.com/soldatov-s/go-gpss/internal"
+)
+
+// ErrFunctionRecursion - function is evaluated again while its argument or
+// attribute is evaluated
+var ErrFunctionRecursion = errors.New("function evaluates itself")
+
+// FunctionType is a type of Function, as D, C, L, E, M in GPSS
+type FunctionType int
+
+const (
+	// FunctionDiscrete (D) - value of the first point with X greater or equal
+	// than argument
+	FunctionDiscrete FunctionType = iota
+	// FunctionContinuous (C) - linear interpolation between points
+	FunctionContinuous
+	// FunctionList (L) - argument is a number of point starting from 1
+	FunctionList
+	// FunctionDiscreteAttr (E) - as FunctionDiscrete, but value is an attribute
+	FunctionDiscreteAttr
+	// FunctionListAttr (M) - as FunctionList, but value is an attribute
+	FunctionListAttr
+)
+
+// FunctionPoint is a point of table of Function
+type FunctionPoint struct {
+	X    float64 // Argument
+	Y    float64 // Value for numeric functions
+	Attr string  // Value for attribute-valued functions, name of function or parameter of transact
+}
+
+// FunctionArgFunc is a function signature for argument of Function
+type FunctionArgFunc func(transact *Transaction) float64
+
+// Function is a GPSS FUNCTION entity, it maps an argument (random number or
+// attribute of transaction) to a value through a piecewise table. Functions
+// are registered in pipeline by name.
+type Function struct {
+	Name     string          // Function name
+	Type     FunctionType    // Function type
+	Points   []FunctionPoint // Points ordered by X
+	Argument FunctionArgFunc // Argument, if nil argument is random number in [0, 1), list function chooses point uniformly
+	Stream   int             // Number of random stream for argument, RN1 by default
+	pipe     *Pipeline
+	busy     bool // Function is being evaluated
+}
+
+// NewFunction creates new Function.
+// name - name of function; typ - type of function; argument - argument of
+// function, if nil argument is random number; points - points of table
+func NewFunction(name string, typ FunctionType, argument FunctionArgFunc, points ...FunctionPoint) *Function {
+	f := &Function{Name: name, Type: typ, Argument: argument, Points: points}
+	if typ != FunctionList && typ != FunctionListAttr {
+		sort.SliceStable(f.Points, func(i, j int) bool { return f.Points[i].X < f.Points[j].X })
+	}
+	return f
+}
+
+// LoadFunctionCSV creates new Function from CSV, each record is a point "x,y",
+// for attribute-valued functions y is a name of function or parameter.
+// Header is skipped if x of the first record is not a number.
+func LoadFunctionCSV(name string, typ FunctionType, argument FunctionArgFunc, r io.Reader) (*Function, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("function %q: %v", name, err)
+	}
+	points := make([]FunctionPoint, 0, len(records))
+	for i, record := range records {
+		x, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("function %q: line %d: %v", name, i+1, err)
+		}
+		point := FunctionPoint{X: x}
+		if typ == FunctionDiscreteAttr || typ == FunctionListAttr {
+			point.Attr = strings.TrimSpace(record[1])
+		} else if point.Y, err = strconv.ParseFloat(strings.TrimSpace(record[1]), 64); err != nil {
+			return nil, fmt.Errorf("function %q: line %d: %v", name, i+1, err)
+		}
+		points = append(points, point)
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("function %q: no points", name)
+	}
+	return NewFunction(name, typ, argument, points...), nil
+}
+
+// point - get index of point for argument
+func (f *Function) point(arg float64) int {
+	if f.Type == FunctionList || f.Type == FunctionListAttr {
+		idx := int(arg) - 1
+		if idx < 0 {
+			return 0
+		}
+		if idx >= len(f.Points) {
+			return len(f.Points) - 1
+		}
+		return idx
+	}
+	idx := sort.Search(len(f.Points), func(i int) bool { return f.Points[i].X >= arg })
+	if idx >= len(f.Points) {
+		return len(f.Points) - 1
+	}
+	return idx
+}
+
+// randomArg - get random argument of function without Argument, for list
+// function it is a number of point chosen uniformly
+func (f *Function) randomArg(r *rand.Rand) float64 {
+	if f.Type == FunctionList || f.Type == FunctionListAttr {
+		return float64(r.Intn(len(f.Points)) + 1)
+	}
+	return r.Float64()
+}
+
+// value - get value of function for argument
+func (f *Function) value(arg float64, transact *Transaction) float64 {
+	if len(f.Points) == 0 {
+		return 0
+	}
+	idx := f.point(arg)
+	switch f.Type {
+	case FunctionContinuous:
+		if idx == 0 || arg >= f.Points[idx].X {
+			return f.Points[idx].Y
+		}
+		p1, p2 := f.Points[idx-1], f.Points[idx]
+		return p1.Y + (p2.Y-p1.Y)*(arg-p1.X)/(p2.X-p1.X)
+	case FunctionDiscreteAttr, FunctionListAttr:
+		return f.attr(f.Points[idx].Attr, transact)
+	}
+	return f.Points[idx].Y
+}
+
+// attr - get value of attribute, it is a function of pipeline or a numeric
+// parameter of transact
+func (f *Function) attr(name string, transact *Transaction) float64 {
+	if f.pipe != nil {
+		if fn := f.pipe.GetFunction(name); fn != nil {
+			return fn.Evaluate(transact)
+		}
+	}
+	if transact == nil {
+		return 0
+	}
+	return GetOr(transact, name, 0.0)
+}
+
+// Evaluate - get value of function for transact. Function, which argument or
+// attribute evaluates the same function, panics with ErrFunctionRecursion, so
+// simulation is stopped with model error.
+func (f *Function) Evaluate(transact *Transaction) float64 {
+	if f.busy {
+		panic(fmt.Errorf("%w: %q", ErrFunctionRecursion, f.Name))
+	}
+	f.busy = true
+	defer func() { f.busy = false }()
+	if f.Argument != nil {
+		return f.value(f.Argument(transact), transact)
+	}
+	if len(f.Points) == 0 {
+		return 0
+	}
+	r := utils.GetDefaultRandom()
+	if f.pipe != nil {
+		r = f.pipe.RN(f.Stream)
+	}
+	return f.value(f.randomArg(r), transact)
+}
+
+// Sample - get value of function for random argument by selected generator,
+// it makes Function a distribution for blocks. Blocks with transact, as
+// Advance, Facility, Split and Queue, evaluate function with argument for
+// transact. Function with argument can not be sampled without transact, it
+// panics, so simulation is stopped with model error.
+func (f *Function) Sample(r *rand.Rand) float64 {
+	if f.Argument != nil {
+		panic(fmt.Sprintf("function %q with argument is sampled without transact", f.Name))
+	}
+	if len(f.Points) == 0 {
+		return 0
+	}
+	return f.value(f.randomArg(r), nil)
+}
+
+// sampleInt - draw int value of distribution for transact, Function with
+// argument is evaluated for transact
+func sampleInt(d distributions.Distribution, r *rand.Rand, transact *Transaction) int {
+	if f, ok := d.(*Function); ok && f.Argument != nil {
+		return int(math.Round(f.Evaluate(transact)))
+	}
+	return distributions.Int(d, r)
+}
+
+// Mean - get mean of function for random argument in [0, 1), for function
+// with argument or list function it is the average of values (random point
+// of list is chosen uniformly), for attribute-valued function it is zero
+func (f *Function) Mean() float64 {
+	if len(f.Points) == 0 {
+		return 0
+	}
+	var mean, prevX, prevY float64
+	switch {
+	case f.Type == FunctionDiscreteAttr || f.Type == FunctionListAttr:
+		return 0
+	case f.Argument != nil || f.Type == FunctionList:
+		for _, p := range f.Points {
+			mean += p.Y
+		}
+		return mean / float64(len(f.Points))
+	case f.Type == FunctionDiscrete:
+		for _, p := range f.Points {
+			x := p.X
+			if x > 1 {
+				x = 1
+			}
+			if x > prevX {
+				mean += p.Y * (x - prevX)
+				prevX = x
+			}
+		}
+		return mean + f.Points[len(f.Points)-1].Y*(1-prevX)
+	default:
+		prevY = f.Points[0].Y
+		for _, p := range f.Points {
+			if p.X <= prevX {
+				prevY = p.Y
+				continue
+			}
+			mean += (p.X - prevX) * (prevY + p.Y) / 2
+			prevX, prevY = p.X, p.Y
+		}
+	}
+	return mean + (1-prevX)*prevY
+}
+
+// Transfer - get function for conditional Transfer, value of function is a
+// number of destination starting from 1
+func (f *Function) Transfer() HandleTransferFunc {
+	return func(obj *Transfer, transact *Transaction) int {
+		return int(f.Evaluate(transact)) - 1
+	}
+}
+
+// AddFunction - register functions in pipeline
+func (p *Pipeline) AddFunction(functions ...*Function) *Pipeline {
+	for _, f := range functions {
+		f.pipe = p
+		p.functions[f.Name] = f
+	}
+	return p
+}
+
+// GetFunction - get function by name, nil if function not found
+func (p *Pipeline) GetFunction(name string) *Function {
+	return p.functions[name]
+}
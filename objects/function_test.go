@@ -0,0 +1,172 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFunction_Evaluate(t *testing.T) {
+	arg := 0.0
+	argument := func(transact *Transaction) float64 { return arg }
+	discrete := NewFunction("discrete", FunctionDiscrete, argument,
+		FunctionPoint{X: 0.5, Y: 2}, FunctionPoint{X: 0.2, Y: 1}, FunctionPoint{X: 1, Y: 3})
+	continuous := NewFunction("continuous", FunctionContinuous, argument,
+		FunctionPoint{X: 0, Y: 0}, FunctionPoint{X: 0.5, Y: 10}, FunctionPoint{X: 1, Y: 30})
+	list := NewFunction("list", FunctionList, argument,
+		FunctionPoint{Y: 5}, FunctionPoint{Y: 7})
+	tests := []struct {
+		f        *Function
+		arg      float64
+		expected float64
+	}{
+		{discrete, 0.1, 1},
+		{discrete, 0.2, 1},
+		{discrete, 0.3, 2},
+		{discrete, 2, 3},
+		{continuous, 0.25, 5},
+		{continuous, 0.75, 20},
+		{continuous, 2, 30},
+		{list, 1, 5},
+		{list, 2, 7},
+		{list, 3, 7},
+	}
+	for _, tt := range tests {
+		arg = tt.arg
+		if v := tt.f.Evaluate(nil); v != tt.expected {
+			t.Error("Function", tt.f.Name, "argument", tt.arg, "expected", tt.expected, "got", v)
+		}
+	}
+}
+
+func TestFunction_RandomList(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	list := NewFunction("list", FunctionList, nil,
+		FunctionPoint{Y: 1}, FunctionPoint{Y: 2}, FunctionPoint{Y: 6})
+	pipe.AddFunction(list)
+	counts := make(map[float64]int)
+	sum := 0.0
+	for i := 0; i < 3000; i++ {
+		v := list.Evaluate(nil)
+		counts[v]++
+		sum += v
+	}
+	for _, p := range list.Points {
+		if counts[p.Y] < 900 || counts[p.Y] > 1100 {
+			t.Error("Choices of point", p.Y, "expected about", 1000, "got", counts[p.Y])
+		}
+	}
+	if mean := sum / 3000; math.Abs(mean-list.Mean()) > 0.2 || list.Mean() != 3 {
+		t.Error("Mean, expected", 3, "got", list.Mean(), "sample mean", mean)
+	}
+}
+
+func TestFunction_Attr(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	typeOf := func(transact *Transaction) float64 { return float64(transact.GetIntParameter("type")) }
+	pipe.AddFunction(
+		NewFunction("service", FunctionListAttr, typeOf,
+			FunctionPoint{Attr: "short"}, FunctionPoint{Attr: "weight"}),
+		NewFunction("short", FunctionDiscrete, nil, FunctionPoint{X: 1, Y: 3}))
+	transact := NewTransaction(pipe)
+	transact.SetParameter("weight", 12)
+	transact.SetParameter("type", 1)
+	if v := pipe.GetFunction("service").Evaluate(transact); v != 3 {
+		t.Error("Value of function, expected", 3, "got", v)
+	}
+	transact.SetParameter("type", 2)
+	if v := pipe.GetFunction("service").Evaluate(transact); v != 12 {
+		t.Error("Value of parameter, expected", 12, "got", v)
+	}
+}
+
+func TestFunction_LoadCSV(t *testing.T) {
+	f, err := LoadFunctionCSV("service", FunctionDiscrete, nil,
+		strings.NewReader("probability,minutes\n0.3,5\n0.8,10\n1,20\n"))
+	if err != nil {
+		t.Fatal("Load error, expected", nil, "got", err)
+	}
+	if len(f.Points) != 3 {
+		t.Fatal("Points, expected", 3, "got", len(f.Points))
+	}
+	if m := f.Mean(); m != 0.3*5+0.5*10+0.2*20 {
+		t.Error("Mean, expected", 0.3*5+0.5*10+0.2*20, "got", m)
+	}
+	if _, err := LoadFunctionCSV("bad", FunctionDiscrete, nil, strings.NewReader("0.3,5\nx,10\n")); err == nil {
+		t.Error("Load error, expected error, got", err)
+	}
+}
+
+func TestFunction_Transfer(t *testing.T) {
+	transfer := NewTransfer("transfer", TransferConditional, 0, nil)
+	pipe, holes := newTransferPipeline(transfer, 2)
+	f := NewFunction("route", FunctionDiscrete, nil, FunctionPoint{X: 0.25, Y: 1}, FunctionPoint{X: 1, Y: 2})
+	pipe.AddFunction(f)
+	transfer.HandleTransfer = f.Transfer()
+	for i := 0; i < 1000; i++ {
+		transfer.AppendTransact(NewTransaction(pipe))
+	}
+	if holes[0].cntTransact < 200 || holes[0].cntTransact > 300 {
+		t.Error("Transacts to first destination, expected about", 250, "got", holes[0].cntTransact)
+	}
+}
+
+func TestFunction_SampleWithArgument(t *testing.T) {
+	parts := NewFunction("parts", FunctionList,
+		func(transact *Transaction) float64 { return float64(transact.GetIntParameter("Type")) },
+		FunctionPoint{Y: 2}, FunctionPoint{Y: 3})
+	pipe := NewPipelineWithSeed("pipe", 1)
+	split := NewSplit("split", 0, 0, nil)
+	split.Distribution = parts
+	holes := []IBaseObj{NewHole("hole A"), NewHole("hole B"), NewHole("hole C")}
+	pipe.Append(split, holes...)
+	pipe.AppendMultiple(holes)
+	transact := NewTransaction(pipe)
+	transact.SetParameter("Type", 1)
+	split.AppendTransact(transact)
+	if split.sumSplit != 2 {
+		t.Error("Parts of transact, expected", 2, "got", split.sumSplit)
+	}
+	// Generator has not transact for argument of function
+	pipe = NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 0, 0, 0, 0, nil)
+	pipe.AddObject(gen).AddObject(NewHole("hole"))
+	gen.Distribution = parts
+	if _, err := pipe.Run(context.Background(), 100); err == nil {
+		t.Error("Run error, expected error, got", nil)
+	}
+}
+
+func TestFunction_Recursion(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	// Attribute of loop is loop itself
+	pipe.AddFunction(NewFunction("loop", FunctionListAttr, nil,
+		FunctionPoint{Attr: "loop"}))
+	advance := NewAdvance("advance", 0, 0)
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(advance).
+		AddObject(NewHole("hole"))
+	advance.Distribution = pipe.GetFunction("loop")
+	if _, err := pipe.Run(context.Background(), 100); !errors.Is(err, ErrFunctionRecursion) {
+		t.Error("Run error, expected", ErrFunctionRecursion, "got", err)
+	}
+	pipe = NewPipelineWithSeed("pipe", 1)
+	var self *Function
+	// Argument of self reads self
+	self = NewFunction("self", FunctionDiscreteAttr,
+		func(transact *Transaction) float64 { return self.Evaluate(transact) },
+		FunctionPoint{X: 1, Attr: "size"})
+	pipe.AddFunction(self)
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrFunctionRecursion) {
+			t.Error("Panic, expected", ErrFunctionRecursion, "got", err)
+		}
+	}()
+	self.Evaluate(NewTransaction(pipe))
+}
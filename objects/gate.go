@@ -0,0 +1,176 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+	"sync"
+)
+
+// IGate implements Gate interface
+type IGate interface {
+	GetLength() int // Get number of waiting transacts
+}
+
+// HandleGateFunc is a condition function signature
+type HandleGateFunc func(obj *Gate, transact *Transaction) bool
+
+// Gating is default condition function, it compares parameters of transact
+// with parameters of gate
+func Gating(obj *Gate, transact *Transaction) bool {
+	for _, v := range obj.parameters {
+		if transact.GetParameter(v.Name) != v.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Gate holds transactions until a condition becomes true (GPSS TEST/GATE in
+// refusal mode). Waiting transactions are kept on a delay chain, the condition
+// is re-evaluated for them each time the state of the model changes.
+type Gate struct {
+	BaseObj
+	// Function for checking
+	HandleChecking HandleGateFunc
+	// Parameters of transact for checking
+	parameters []Parameter
+	// Max number of waiting transacts
+	maxContent int
+	// Number of waiting transacts, weighted by time
+	content timeWeighted
+	// Model time of input for each waiting transact
+	timeOfInput map[int]int
+	// Sum time of waiting
+	sumTimeWait float64
+	// For counting the transacts that go through gate
+	sumEntries float64
+	// For counting the transacts that go through gate without waiting
+	sumZeroEntries float64
+}
+
+// NewGate creates new Gate.
+// name - name of object; hndl - condition function, if nil parameters of
+// transact are compared with parameters; parameters - parameters for checking
+func NewGate(name string, hndl HandleGateFunc, parameters ...Parameter) *Gate {
+	obj := &Gate{parameters: parameters}
+	obj.BaseObj.Init(name)
+	obj.timeOfInput = make(map[int]int)
+	if hndl != nil {
+		obj.HandleChecking = hndl
+	} else {
+		obj.HandleChecking = Gating
+	}
+	return obj
+}
+
+// HandleTransact handle transact, transact goes to destination if condition
+// is true. Returns true if transact leaved gate.
+func (obj *Gate) HandleTransact(transact *Transaction) bool {
+	if !obj.HandleChecking(obj, transact) {
+		return false
+	}
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateContent - totalize content of gate, it is called after each change
+// of content
+func (obj *Gate) updateContent() {
+	obj.content.set(obj.Pipe.ModelTime, float64(obj.tb.Len()))
+}
+
+// averageContent - get average content of gate, weighted by time
+func (obj *Gate) averageContent() float64 {
+	return obj.content.average(obj.Pipe.ModelTime)
+}
+
+// averageTime - get average waiting time of entries, time of transacts which
+// are still waiting is counted up to model time
+func (obj *Gate) averageTime() float64 {
+	return ratio(obj.content.area(obj.Pipe.ModelTime), obj.sumEntries)
+}
+
+// GetLength get number of waiting transacts
+func (obj *Gate) GetLength() int {
+	return obj.tb.Len()
+}
+
+// GetBlocked - get waiting transacts
+func (obj *Gate) GetBlocked() []*Transaction {
+	var blocked []*Transaction
+	for _, tr := range obj.tb.List() {
+		blocked = append(blocked, tr.transact)
+	}
+	return blocked
+}
+
+// Release - re-evaluate condition for waiting transact and send it to
+// destination if condition is true
+func (obj *Gate) Release(transact *Transaction) bool {
+	if obj.tb.Item(transact.GetID()) == nil || !obj.HandleTransact(transact) {
+		return false
+	}
+	timeWait := obj.Pipe.ModelTime - obj.timeOfInput[transact.GetID()]
+	transact.AddQueueTime(timeWait)
+	obj.sumTimeWait += float64(timeWait)
+	delete(obj.timeOfInput, transact.GetID())
+	obj.tb.Remove(transact)
+	obj.updateContent()
+	return true
+}
+
+// HandleTransacts handle transacts, re-evaluate condition for waiting transacts
+func (obj *Gate) HandleTransacts(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for _, transact := range obj.GetBlocked() {
+		obj.Release(transact)
+	}
+}
+
+// AppendTransact append transact to object. Transact passes the gate at once
+// if condition is true and next object is free, otherwise transact waits in
+// gate.
+func (obj *Gate) AppendTransact(transact *Transaction) bool {
+	obj.BaseObj.AppendTransact(transact)
+	transact.SetHolder(obj.name)
+	obj.sumEntries++
+	if obj.HandleTransact(transact) {
+		obj.sumZeroEntries++
+		return true
+	}
+	obj.timeOfInput[transact.GetID()] = obj.Pipe.ModelTime
+	obj.tb.PushByPriority(transact)
+	obj.updateContent()
+	if obj.maxContent < obj.tb.Len() {
+		obj.maxContent = obj.tb.Len()
+	}
+	return true
+}
+
+// Report - print report about object
+func (obj *Gate) Report() {
+	obj.BaseObj.Report()
+	fmt.Printf("Max content \t%d\tTotal entries \t%2.f\tZero entries \t%2.f\tCurrent contents \t%d\n",
+		obj.maxContent, obj.sumEntries, obj.sumZeroEntries, obj.tb.Len())
+	fmt.Printf("Average content \t%.2f\tAverage time/trans \t%.2f\n\n",
+		obj.averageContent(), obj.averageTime())
+}
+
+// Stats - get statistics of object
+func (obj *Gate) Stats() map[string]float64 {
+	return map[string]float64{
+		"max_content":     float64(obj.maxContent),
+		"entries":         obj.sumEntries,
+		"zero_entries":    obj.sumZeroEntries,
+		"current_content": float64(obj.tb.Len()),
+		"average_content": obj.averageContent(),
+		"average_time":    obj.averageTime(),
+	}
+}
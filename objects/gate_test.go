@@ -0,0 +1,52 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGate_WaitForCondition(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	blocker := NewFacility("blocker", 10, 0)
+	gate := NewGate("gate", func(obj *Gate, transact *Transaction) bool {
+		return blocker.IsEmpty()
+	})
+	hole := NewHole("hole")
+	pipe.Append(NewGenerator("first", 0, 0, 0, 1, nil), blocker)
+	pipe.Append(blocker, NewHole("first out"))
+	pipe.Append(NewGenerator("waiting", 2, 0, 0, 3, nil), gate)
+	pipe.Append(gate, hole)
+	pipe.Append(hole)
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// Transacts wait from 2 to 10
+	if hole.cntTransact != 3 || hole.sumLife != 3*8 {
+		t.Error("Sum life, expected", 3*8, "got", hole.sumLife)
+	}
+	stats := res.Object("gate").Stats
+	if stats["max_content"] != 3 || stats["current_content"] != 0 || stats["average_time"] != 8 {
+		t.Error("Gate stats, expected", 3, 0, 8, "got",
+			stats["max_content"], stats["current_content"], stats["average_time"])
+	}
+}
+
+func TestGate_Parameters(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gate := NewGate("gate", nil, Parameter{Name: "State", Value: "ready"})
+	pipe.Append(gate, NewHole("hole"))
+	transact := NewTransaction(pipe)
+	gate.AppendTransact(transact)
+	if gate.GetLength() != 1 {
+		t.Fatal("Waiting transacts, expected", 1, "got", gate.GetLength())
+	}
+	transact.SetParameter("State", "ready")
+	if !gate.Release(transact) || gate.GetLength() != 0 {
+		t.Error("Waiting transacts after release, expected", 0, "got", gate.GetLength())
+	}
+}
@@ -6,8 +6,10 @@ package objects
 
 import (
 	"fmt"
-	"sync"
+	"math"
+	"math/rand"
 
+	"github.com/soldatov-s/go-gpss/distributions"
 	utils "github.com/soldatov-s/go-gpss/internal"
 )
 
@@ -20,29 +22,114 @@ type IGenerator interface {
 // HandleBornFunc is a born transact function signature
 type HandleBornFunc func(obj *Generator) int
 
+// ParameterTemplate is an initial parameter of generated transactions, value
+// is constant or sampled for each transaction
+type ParameterTemplate struct {
+	Name  string                         // Name of parameter
+	Value func(r *rand.Rand) interface{} // Function for generate value of parameter
+}
+
+// ConstParameter creates template of parameter with constant value
+func ConstParameter(name string, value interface{}) ParameterTemplate {
+	return ParameterTemplate{Name: name, Value: func(r *rand.Rand) interface{} { return value }}
+}
+
+// SampledParameter creates template of parameter with float64 value drawn
+// from distribution, for example cost or weight
+func SampledParameter(name string, d distributions.Distribution) ParameterTemplate {
+	return ParameterTemplate{Name: name, Value: func(r *rand.Rand) interface{} { return d.Sample(r) }}
+}
+
+// SampledIntParameter creates template of parameter with value drawn from
+// distribution and rounded to int, for example number of items
+func SampledIntParameter(name string, d distributions.Distribution) ParameterTemplate {
+	return ParameterTemplate{Name: name, Value: func(r *rand.Rand) interface{} { return distributions.Int(d, r) }}
+}
+
+// ChoiceParameter creates template of parameter with value chosen from
+// values by weights, if weights is nil all values have same weight, it
+// panics if weights are invalid as NewEmpirical
+func ChoiceParameter(name string, values []interface{}, weights []float64) ParameterTemplate {
+	indexes := make([]float64, len(values))
+	for i := range indexes {
+		indexes[i] = float64(i)
+	}
+	d := distributions.NewEmpirical(indexes, weights)
+	return ParameterTemplate{Name: name, Value: func(r *rand.Rand) interface{} {
+		if len(values) == 0 {
+			return nil
+		}
+		return values[int(d.Sample(r))]
+	}}
+}
+
 // A Generator sequentially generates transactions
 type Generator struct {
 	BaseObj
-	Interval    int            // Mean inter generation time
-	Modificator int            // Inter generation time half-range
-	Start       int            // Start delay time
-	Count       int            // Creation limit. Max count of transactions.
-	id          int            // ID of new transaction
-	nextborn    int            // The time when will create new transaction
-	HandleBorn  HandleBornFunc // Function for generate born time of transaction
+	Interval     int                        // Mean inter generation time
+	Modificator  int                        // Inter generation time half-range
+	Distribution distributions.Distribution // Distribution of inter generation time, used instead of Interval and Modificator if set
+	Start        int                        // Start delay time
+	Count        int                        // Creation limit. Max count of transactions.
+	Stream       int                        // Number of random stream, RN1 by default
+	Priority     int                        // Priority of generated transactions
+	id           int                        // ID of new transaction
+	nextborn     int                        // The time when will create new transaction
+	HandleBorn   HandleBornFunc             // Function for generate born time of transaction
+	event        *Event                     // Scheduled wake-up of generator
+	Schedule     *RateSchedule              // Time-varying arrival rate, used instead of Interval and Modificator if set
+	rateTime     float64                    // Time of last arrival by schedule
+	cntWindow    map[int]float64            // Counter of arrivals in each window of schedule
+	Trace        []TraceRecord              // Recorded arrivals, replayed instead of sampling if set
+	Batch        distributions.Distribution // Number of transactions per arrival, 1 if not set
+	Parameters   []ParameterTemplate        // Initial parameters of generated transactions
+	traceIdx     int                        // Index of next record of trace
 }
 
-// GenerateBorn - default function for generate born time of transaction
+// GenerateBorn - default function for generate born time of transaction.
+// Returns -1 if schedule or trace has no more arrivals, samples of
+// Distribution are not less than 0.
 func GenerateBorn(obj *Generator) int {
-	var born int
-	born += obj.Interval
-	if obj.Modificator > 0 {
-		born += utils.GetRandom(-obj.Modificator, obj.Modificator)
-	}
+	r := utils.GetDefaultRandom()
+	var modelTime int
+	horizon := math.Inf(1)
 	if obj.Pipe != nil {
-		born += obj.Pipe.ModelTime
+		r = obj.Pipe.RN(obj.Stream)
+		modelTime = obj.Pipe.ModelTime
+		if obj.Pipe.SimTime > 0 {
+			horizon = float64(obj.Pipe.SimTime)
+		}
 	}
-	return born
+	if obj.Trace != nil {
+		if obj.traceIdx >= len(obj.Trace) {
+			return -1
+		}
+		if obj.Trace[obj.traceIdx].Time < modelTime {
+			return modelTime
+		}
+		return obj.Trace[obj.traceIdx].Time
+	}
+	if obj.Schedule != nil {
+		obj.rateTime = obj.Schedule.next(r, math.Max(obj.rateTime, float64(modelTime)), horizon)
+		if math.IsInf(obj.rateTime, 1) {
+			return -1
+		}
+		return int(obj.rateTime)
+	}
+	if obj.Distribution != nil {
+		// Negative inter generation time means arrival right now, -1 is
+		// reserved for end of arrivals
+		born := distributions.Int(obj.Distribution, r)
+		if born < 0 {
+			born = 0
+		}
+		return born + modelTime
+	}
+	born := obj.Interval
+	if obj.Modificator > 0 {
+		born += utils.GetRandomFrom(r, -obj.Modificator, obj.Modificator)
+	}
+	return born + modelTime
 }
 
 // NewGenerator creates new Generator.
@@ -58,60 +145,134 @@ func NewGenerator(name string, interval, modificator, start, count int, hndl Han
 	obj.Start = start
 	obj.Count = count
 	obj.id = 1
+	obj.cntWindow = make(map[int]float64)
 	if hndl != nil {
 		obj.HandleBorn = hndl
 	} else {
 		obj.HandleBorn = GenerateBorn
 	}
-	obj.nextborn = obj.HandleBorn(obj)
 	return obj
 }
 
+// start - schedule the first born of transaction when simulation starts.
+// Parameters of templates or trace with reserved names stop simulation with
+// model error.
+func (obj *Generator) start() {
+	for _, p := range obj.Parameters {
+		if err := checkParameterName(p.Name); err != nil {
+			panic(fmt.Errorf("parameter template: %w", err))
+		}
+	}
+	for _, rec := range obj.Trace {
+		for _, p := range rec.Parameters {
+			if err := checkParameterName(p.Name); err != nil {
+				panic(fmt.Errorf("trace: %w", err))
+			}
+		}
+	}
+	obj.Pipe.Cancel(obj.event)
+	obj.scheduleBorn()
+}
+
+// scheduleBorn - generate born time of next transaction and schedule wake-up
+// of generator at this time, negative born time stops generator
+func (obj *Generator) scheduleBorn() {
+	obj.nextborn = obj.HandleBorn(obj)
+	if obj.nextborn < 0 {
+		utils.Log.Trace.Println("Stop generate")
+		return
+	}
+	obj.event = obj.Pipe.Schedule(obj, nil, obj.nextborn-obj.Pipe.ModelTime)
+}
+
 // GenerateTransact - generates transaction and it send into the simulation
 func (obj *Generator) GenerateTransact() {
 	var isTransactSended bool
 	utils.Log.Trace.Println("Generate transact ", obj.id)
 	t := NewTransaction(obj.Pipe)
 	t.SetHolder(obj.name)
+	t.SetPriority(obj.Priority)
+	for _, p := range obj.Parameters {
+		t.SetParameters([]Parameter{{Name: p.Name, Value: p.Value(obj.Pipe.RN(obj.Stream))}})
+	}
+	if obj.traceIdx < len(obj.Trace) {
+		t.SetParameters(obj.Trace[obj.traceIdx].Parameters)
+	}
 	for _, v := range obj.GetDst() {
 		isTransactSended = isTransactSended || v.AppendTransact(t)
 	}
 	if isTransactSended {
 		obj.id++
+		if obj.Schedule != nil {
+			obj.cntWindow[obj.Schedule.window(float64(obj.Pipe.ModelTime))]++
+		}
 	}
 }
 
-// HandleTransacts handle transacts in goroutine
-func (obj *Generator) HandleTransacts(wg *sync.WaitGroup) {
-	if (obj.Count != 0 && obj.id > obj.Count) ||
-		(obj.nextborn != obj.Pipe.ModelTime) {
-		wg.Done()
+// generateBatch - generates batch of transactions for one arrival, size of
+// batch is drawn from Batch, transactions of trace record get same parameters
+func (obj *Generator) generateBatch() {
+	size := 1
+	if obj.Batch != nil {
+		size = distributions.Int(obj.Batch, obj.Pipe.RN(obj.Stream))
+	}
+	for i := 0; i < size && (obj.Count == 0 || obj.id <= obj.Count); i++ {
+		obj.GenerateTransact()
+	}
+	obj.traceIdx++
+}
+
+// HandleEvent generates transactions at the born time and schedules the next born
+func (obj *Generator) HandleEvent(e *Event) {
+	if obj.Count != 0 && obj.id > obj.Count {
 		return
 	}
-	go func() {
-		defer func() {
-			obj.nextborn = obj.HandleBorn(obj)
-			wg.Done()
-		}()
-		// Generate trasact one by one
-		if obj.Count == 0 {
-			obj.GenerateTransact()
-			return
+	// Generate trasact (or batch) one by one
+	if obj.Count == 0 {
+		obj.generateBatch()
+		obj.scheduleBorn()
+		return
+	}
+	// Generate transact one by one by schedule, trace or batches until
+	// creation limit
+	if obj.Schedule != nil || obj.Trace != nil || obj.Batch != nil {
+		obj.generateBatch()
+		if obj.id <= obj.Count {
+			obj.scheduleBorn()
 		}
-		// Generate all transact at once
-		for {
-			obj.GenerateTransact()
-			if obj.id > obj.Count {
-				utils.Log.Trace.Println("Stop generate")
-				return
-			}
+		return
+	}
+	// Generate all transact at once
+	for {
+		obj.GenerateTransact()
+		if obj.id > obj.Count {
+			utils.Log.Trace.Println("Stop generate")
+			return
 		}
-	}()
+	}
 }
 
 // Report - print report about object
 func (obj *Generator) Report() {
 	obj.BaseObj.Report()
 	fmt.Println("Generated", obj.id-1)
+	if obj.Schedule != nil {
+		for i, w := range obj.Schedule.Windows {
+			fmt.Printf("Window from %d\tRate %.4f\tArrivals %.2f\n", w.Start, w.Rate, obj.cntWindow[i])
+		}
+	}
 	fmt.Println()
 }
+
+// Stats - get statistics of object
+func (obj *Generator) Stats() map[string]float64 {
+	stats := map[string]float64{
+		"generated": float64(obj.id - 1),
+	}
+	if obj.Schedule != nil {
+		for i, w := range obj.Schedule.Windows {
+			stats[fmt.Sprintf("window_%d", w.Start)] = obj.cntWindow[i]
+		}
+	}
+	return stats
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+func TestGenerator_BatchAndParameters(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 0, nil)
+	gen.Batch = distributions.NewConstant(3)
+	gen.Parameters = []ParameterTemplate{
+		ConstParameter("table", "window"),
+		SampledIntParameter("dishes", distributions.NewUniform(1, 4)),
+		SampledParameter("cost", distributions.NewTruncatedNormal(15, 2, 10, 20)),
+		ChoiceParameter("class", []interface{}{"A", "B"}, []float64{1, 3}),
+	}
+	classes := make(map[interface{}]int)
+	times := make(map[int]int)
+	check := NewCheck("check", func(obj *Check, transact *Transaction) bool {
+		classes[transact.GetParameter("class")]++
+		times[obj.Pipe.ModelTime]++
+		dishes := transact.GetIntParameter("dishes")
+		cost, ok := transact.GetParameter("cost").(float64)
+		return transact.GetParameter("table") == "window" && dishes >= 1 && dishes <= 4 &&
+			ok && cost >= 10 && cost <= 20 && cost != float64(int(cost))
+	}, nil)
+	pipe.AddObject(gen).AddObject(check).AddObject(NewHole("hole"))
+	if _, err := pipe.Run(context.Background(), 10000); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if check.cntFalse != 0 {
+		t.Error("Transacts with wrong parameters, expected", 0, "got", check.cntFalse)
+	}
+	for modelTime, cnt := range times {
+		if cnt != 3 {
+			t.Error("Batch at", modelTime, "expected", 3, "got", cnt)
+		}
+	}
+	if classes["B"] < 2*classes["A"] {
+		t.Error("Class B must be about 3 times more often than class A, got", classes)
+	}
+}
+
+func TestGenerator_BatchCount(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 5, nil)
+	gen.Batch = distributions.NewConstant(2)
+	hole := NewHole("hole")
+	pipe.AddObject(gen).AddObject(hole)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// Batches at 10, 20 and 30, the last batch is limited by creation limit
+	if hole.cntTransact != 5 || hole.sumLife != 0 {
+		t.Error("Killed transacts, expected", 5, "got", hole.cntTransact)
+	}
+	if gen.event.Time != 30 {
+		t.Error("Time of the last batch, expected", 30, "got", gen.event.Time)
+	}
+}
+
+func TestGenerator_ReservedParameters(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 0, nil)
+	gen.Parameters = []ParameterTemplate{
+		ConstParameter("size", 1),
+		ChoiceParameter("born", []interface{}{1, 2}, nil),
+	}
+	hole := NewHole("hole")
+	pipe.Append(gen, hole)
+	pipe.Append(hole)
+	res, err := pipe.Run(context.Background(), 100)
+	if !errors.Is(err, ErrReservedParameter) {
+		t.Fatal("Run error, expected", ErrReservedParameter, "got", err)
+	}
+	if res.ModelTime != 0 || hole.cntTransact != 0 {
+		t.Error("Model time and killed transacts, expected", 0, 0, "got", res.ModelTime, hole.cntTransact)
+	}
+}
+
+func TestGenerator_NegativeInterval(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 0, 0, 0, 0, nil)
+	// Many of inter generation times are negative
+	gen.Distribution = distributions.NewNormal(1, 3)
+	hole := NewHole("hole")
+	pipe.Append(gen, hole)
+	pipe.Append(hole)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if hole.cntTransact < 30 {
+		t.Error("Killed transacts, expected at least", 30, "got", hole.cntTransact)
+	}
+	if gen.nextborn < 100 {
+		t.Error("Next born, expected after", 100, "got", gen.nextborn)
+	}
+}
@@ -6,7 +6,6 @@ package objects
 
 import (
 	"fmt"
-	"sync"
 )
 
 // Hole in which fall in transactions
@@ -36,26 +35,11 @@ func (obj *Hole) HandleTransact(transact *Transaction) {
 	}
 }
 
-// HandleTransacts handle transacts in goroutine
-func (obj *Hole) HandleTransacts(wg *sync.WaitGroup) {
-	if obj.tb.Len() == 0 {
-		wg.Done()
-		return
-	}
-	go func() {
-		defer wg.Done()
-		transacts := obj.tb.Items()
-		for _, tr := range transacts {
-			obj.HandleTransact(tr.transact)
-		}
-	}()
-}
-
-// AppendTransact append transact to object
+// AppendTransact append transact to object, transact is killed immediately
 func (obj *Hole) AppendTransact(transact *Transaction) bool {
 	obj.BaseObj.AppendTransact(transact)
 	transact.SetHolder(obj.name)
-	obj.tb.Push(transact)
+	obj.HandleTransact(transact)
 	return true
 }
 
@@ -67,3 +51,12 @@ func (obj *Hole) Report() {
 	fmt.Printf("Average life %.2f\n", obj.sumLife/obj.cntTransact)
 	fmt.Println()
 }
+
+// Stats - get statistics of object
+func (obj *Hole) Stats() map[string]float64 {
+	return map[string]float64{
+		"killed":          obj.cntTransact,
+		"average_advance": ratio(obj.sumAdvance, obj.cntTransact),
+		"average_life":    ratio(obj.sumLife, obj.cntTransact),
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	// ErrParameterNotFound - transact has not parameter
+	ErrParameterNotFound = errors.New("parameter not found")
+	// ErrParameterType - parameter has type which can not be converted to
+	// requested or declared type
+	ErrParameterType = errors.New("invalid parameter type")
+	// ErrReservedParameter - name of parameter is reserved for built-in
+	// state of transact
+	ErrReservedParameter = errors.New("parameter name is reserved")
+)
+
+// reservedParameters - names of parameters, which keep built-in state of
+// transact
+var reservedParameters = map[string]bool{
+	"id": true, "born": true, "advance": true, "timequeue": true, "ticks": true,
+	"rip": true, "holder": true, "part": true, "parts": true, "parent_id": true,
+}
+
+// checkParameterName - check that parameter does not overwrite built-in state
+// of transact
+func checkParameterName(name string) error {
+	if reservedParameters[name] {
+		return fmt.Errorf("%w: %q", ErrReservedParameter, name)
+	}
+	return nil
+}
+
+// ParameterKind is a type of parameter declared in pipeline
+type ParameterKind int
+
+const (
+	// ParameterAny - parameter may have any value
+	ParameterAny ParameterKind = iota
+	// ParameterInt - parameter is int, whole numbers are converted to int
+	ParameterInt
+	// ParameterFloat - parameter is float64, numbers are converted to float64
+	ParameterFloat
+	// ParameterString - parameter is string
+	ParameterString
+	// ParameterBool - parameter is bool
+	ParameterBool
+	// ParameterDuration - parameter is time.Duration, whole numbers are
+	// converted to time.Duration
+	ParameterDuration
+)
+
+// String - get name of kind
+func (k ParameterKind) String() string {
+	switch k {
+	case ParameterInt:
+		return "int"
+	case ParameterFloat:
+		return "float64"
+	case ParameterString:
+		return "string"
+	case ParameterBool:
+		return "bool"
+	case ParameterDuration:
+		return "time.Duration"
+	}
+	return "any"
+}
+
+// ParameterSchema is a set of types of parameters by name
+type ParameterSchema map[string]ParameterKind
+
+// DeclareParameters - declare types of parameters of transacts in pipeline.
+// Value of declared parameter is converted to declared type when it is set,
+// value which can not be converted stops simulation with model error.
+func (p *Pipeline) DeclareParameters(schema ParameterSchema) *Pipeline {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	if p.parameters == nil {
+		p.parameters = make(ParameterSchema)
+	}
+	for name, kind := range schema {
+		p.parameters[name] = kind
+	}
+	return p
+}
+
+// DeclaredKind - get declared type of parameter, ParameterAny if parameter is
+// not declared
+func (p *Pipeline) DeclaredKind(name string) ParameterKind {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	return p.parameters[name]
+}
+
+// Get - get parameter of transact converted to type T, false if parameter
+// not found or can not be converted. Parameters are stored as interface{}
+// values, Get reads them without type assertions, numbers are converted
+// between int, int64, float64 and time.Duration.
+func Get[T any](t *Transaction, name string) (T, bool) {
+	v, err := get[T](t, name)
+	return v, err == nil
+}
+
+// GetOr - get parameter of transact converted to type T, def if parameter
+// not found or can not be converted
+func GetOr[T any](t *Transaction, name string, def T) T {
+	if v, err := get[T](t, name); err == nil {
+		return v
+	}
+	return def
+}
+
+// Require - get parameter of transact converted to type T. If parameter not
+// found or can not be converted, simulation is stopped with model error and
+// zero value is returned. Transact without pipeline panics with the error.
+func Require[T any](t *Transaction, name string) T {
+	v, err := get[T](t, name)
+	if err != nil {
+		if t.pipe == nil {
+			panic(err)
+		}
+		t.pipe.Fail(err)
+	}
+	return v
+}
+
+// get - get parameter of transact converted to type T
+func get[T any](t *Transaction, name string) (T, error) {
+	var zero T
+	value, ok := t.parameters[name]
+	if !ok || value == nil {
+		return zero, fmt.Errorf("%w: %q of transact %v", ErrParameterNotFound, name, t.parameters["id"])
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	if v, ok := convert(value, any(zero)); ok {
+		return v.(T), nil
+	}
+	return zero, fmt.Errorf("%w: %q of transact %v is %T, not %T",
+		ErrParameterType, name, t.parameters["id"], value, zero)
+}
+
+// convert - convert numeric value to type of target, int and time.Duration
+// accept only whole numbers
+func convert(value, target interface{}) (interface{}, bool) {
+	f, ok := toFloat64(value)
+	if !ok {
+		return nil, false
+	}
+	whole := f == math.Trunc(f) && math.Abs(f) < 1<<53
+	switch target.(type) {
+	case float64:
+		return f, true
+	case float32:
+		return float32(f), true
+	case int:
+		return int(f), whole
+	case int64:
+		return int64(f), whole
+	case time.Duration:
+		return time.Duration(f), whole
+	}
+	return nil, false
+}
+
+// checkParameter - convert value of parameter to declared type
+func (t *Transaction) checkParameter(name string, value interface{}) (interface{}, error) {
+	if t.pipe == nil || value == nil {
+		return value, nil
+	}
+	kind := t.pipe.DeclaredKind(name)
+	var (
+		v  interface{}
+		ok bool
+	)
+	switch kind {
+	case ParameterAny:
+		return value, nil
+	case ParameterInt:
+		v, ok = coerce[int](value)
+	case ParameterFloat:
+		v, ok = coerce[float64](value)
+	case ParameterString:
+		v, ok = coerce[string](value)
+	case ParameterBool:
+		v, ok = coerce[bool](value)
+	case ParameterDuration:
+		v, ok = coerce[time.Duration](value)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %q of transact %v is declared as %s, got %T",
+			ErrParameterType, name, t.parameters["id"], kind, value)
+	}
+	return v, nil
+}
+
+// coerce - convert value to type T
+func coerce[T any](value interface{}) (interface{}, bool) {
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+	var zero T
+	return convert(value, any(zero))
+}
@@ -0,0 +1,137 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	transact := NewTransaction(pipe)
+	transact.SetParameters([]Parameter{
+		{Name: "count", Value: 3},
+		{Name: "cost", Value: 2.5},
+		{Name: "whole", Value: 4.0},
+		{Name: "name", Value: "Table 1"},
+		{Name: "delay", Value: 5 * time.Second},
+	})
+	if v, ok := Get[int](transact, "count"); !ok || v != 3 {
+		t.Error("Get int, expected", 3, "got", v, ok)
+	}
+	if v, ok := Get[float64](transact, "count"); !ok || v != 3 {
+		t.Error("Get int as float64, expected", 3, "got", v, ok)
+	}
+	if v, ok := Get[int](transact, "whole"); !ok || v != 4 {
+		t.Error("Get whole float64 as int, expected", 4, "got", v, ok)
+	}
+	if v, ok := Get[int](transact, "cost"); ok {
+		t.Error("Get fractional float64 as int, expected", false, "got", v, ok)
+	}
+	if v, ok := Get[time.Duration](transact, "count"); !ok || v != 3 {
+		t.Error("Get int as duration, expected", 3, "got", v, ok)
+	}
+	if v, ok := Get[float64](transact, "delay"); !ok || v != float64(5*time.Second) {
+		t.Error("Get duration as float64, expected", float64(5*time.Second), "got", v, ok)
+	}
+	if v, ok := Get[string](transact, "count"); ok {
+		t.Error("Get int as string, expected", false, "got", v, ok)
+	}
+	if v, ok := Get[string](transact, "missing"); ok {
+		t.Error("Get missing parameter, expected", false, "got", v, ok)
+	}
+	if v := GetOr(transact, "name", ""); v != "Table 1" {
+		t.Error("GetOr string, expected", "Table 1", "got", v)
+	}
+	transact.SetParameters([]Parameter{{Name: "name", Value: nil}})
+	if v := GetOr(transact, "name", "none"); v != "none" {
+		t.Error("GetOr cleared parameter, expected", "none", "got", v)
+	}
+	if pipe.Err() != nil {
+		t.Error("Model error, expected", nil, "got", pipe.Err())
+	}
+}
+
+func TestRequire(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	transact := NewTransaction(pipe)
+	if v := transact.GetIntParameter("missing"); v != 0 {
+		t.Error("GetIntParameter missing, expected", 0, "got", v)
+	}
+	if err := pipe.Err(); !errors.Is(err, ErrParameterNotFound) {
+		t.Error("Model error, expected", ErrParameterNotFound, "got", err)
+	}
+	pipe = NewPipelineWithSeed("pipe", 1)
+	transact = NewTransaction(pipe)
+	transact.SetParameter("name", 1.5)
+	if v := transact.GetStringParameter("name"); v != "" {
+		t.Error("GetStringParameter float64, expected", "", "got", v)
+	}
+	if err := pipe.Err(); !errors.Is(err, ErrParameterType) {
+		t.Error("Model error, expected", ErrParameterType, "got", err)
+	}
+}
+
+func TestRequire_WithoutPipeline(t *testing.T) {
+	transact := &Transaction{parameters: map[string]interface{}{"size": 2}}
+	if v := Require[int](transact, "size"); v != 2 {
+		t.Error("Require int, expected", 2, "got", v)
+	}
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrParameterNotFound) {
+			t.Error("Panic, expected", ErrParameterNotFound, "got", err)
+		}
+	}()
+	Require[int](transact, "missing")
+}
+
+func TestPipeline_DeclareParameters(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	pipe.DeclareParameters(ParameterSchema{
+		"count": ParameterInt,
+		"cost":  ParameterFloat,
+		"name":  ParameterString,
+	})
+	transact := NewTransaction(pipe)
+	transact.SetParameters([]Parameter{
+		{Name: "count", Value: 2.0},
+		{Name: "cost", Value: 3},
+		{Name: "other", Value: "any"},
+	})
+	if v, ok := transact.GetParameter("count").(int); !ok || v != 2 {
+		t.Error("Declared int parameter, expected", 2, "got", transact.GetParameter("count"))
+	}
+	if v, ok := transact.GetParameter("cost").(float64); !ok || v != 3 {
+		t.Error("Declared float64 parameter, expected", 3.0, "got", transact.GetParameter("cost"))
+	}
+	if pipe.Err() != nil {
+		t.Fatal("Model error, expected", nil, "got", pipe.Err())
+	}
+	transact.SetParameter("name", 7)
+	if v := transact.GetParameter("name"); v != nil {
+		t.Error("Declared string parameter set by int, expected", nil, "got", v)
+	}
+	if err := pipe.Err(); !errors.Is(err, ErrParameterType) {
+		t.Error("Model error, expected", ErrParameterType, "got", err)
+	}
+}
+
+func TestPipeline_RunParameterError(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	pipe.DeclareParameters(ParameterSchema{"count": ParameterInt})
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(NewAssign("assign", Parameter{Name: "count", Value: 1.5})).
+		AddObject(NewHole("hole"))
+	res, err := pipe.Run(context.Background(), 1000)
+	if !errors.Is(err, ErrParameterType) {
+		t.Fatal("Run error, expected", ErrParameterType, "got", err)
+	}
+	if res.ModelTime != 10 {
+		t.Error("Model time, expected", 10, "got", res.ModelTime)
+	}
+}
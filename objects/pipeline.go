@@ -5,24 +5,50 @@
 package objects
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sort"
 	"sync"
+	"time"
 
 	utils "github.com/soldatov-s/go-gpss/internal"
 )
 
 // Pipeline is structure for pipeline
 type Pipeline struct {
-	Name      string              // Pipeline name
-	objects   map[string]IBaseObj // Maps of objects
-	lstObject []IBaseObj          // Last object in map of objects
-	ModelTime int                 // Current Model Time
-	Done      chan struct{}       // Chan for done
-	SimTime   int                 // Simulation time
-	id        int                 // ID of new transaction
-	doneHndl  []func(p *Pipeline) // Handler of done event
+	Name       string                   // Pipeline name
+	objects    map[string]IBaseObj      // Maps of objects
+	lstObject  []IBaseObj               // Last object in map of objects
+	ModelTime  int                      // Current Model Time
+	Done       chan struct{}            // Chan for done
+	SimTime    int                      // Simulation time
+	id         int                      // ID of new transaction
+	doneHndl   []func(p *Pipeline)      // Handler of done event
+	events     *EventChain              // Future events chain
+	seed       int64                    // Seed of random streams
+	streams    map[int]*rand.Rand       // Random streams RN1..RNn
+	sorted     []IBaseObj               // Objects ordered by ID
+	terminate  []func(p *Pipeline) bool // Termination conditions
+	functions  map[string]*Function     // Functions by name
+	tables     map[string]*Table        // Tables by name
+	saveValues map[string]*SaveValue    // SaveValues by name
+	matrices   map[string]*Matrix       // Matrices by name
+	parameters ParameterSchema          // Declared types of parameters
+	err        error                    // Model error
+	started    bool                     // First events of objects are scheduled
+	pending    map[string]bool          // Objects touched since last scan
+	blocking   map[string]bool          // Objects which may hold blocked transacts
+	stopOnce   *sync.Once
+	mu         *sync.Mutex
+}
+
+// starter implements object which schedules its first events when simulation
+// starts, so fields of object may be set after it is added to pipeline
+type starter interface {
+	start()
 }
 
 type IPipeline interface {
@@ -30,14 +56,61 @@ type IPipeline interface {
 	AddObject(obj IBaseObj) IBaseObj
 }
 
-// NewPipeline create new Pipeline
+// NewPipeline create new Pipeline, random streams are seeded by current time
 func NewPipeline(name string, doneHndl ...func(p *Pipeline)) *Pipeline {
+	return NewPipelineWithSeed(name, time.Now().UnixNano(), doneHndl...)
+}
+
+// NewPipelineWithSeed create new Pipeline with seed for random streams. Two runs
+// of same model with same seed give identical results.
+func NewPipelineWithSeed(name string, seed int64, doneHndl ...func(p *Pipeline)) *Pipeline {
 	return &Pipeline{
-		objects:  make(map[string]IBaseObj),
-		Name:     name,
-		Done:     make(chan struct{}),
-		doneHndl: doneHndl,
+		objects:    make(map[string]IBaseObj),
+		Name:       name,
+		Done:       make(chan struct{}),
+		doneHndl:   doneHndl,
+		events:     NewEventChain(),
+		seed:       seed,
+		streams:    make(map[int]*rand.Rand),
+		functions:  make(map[string]*Function),
+		tables:     make(map[string]*Table),
+		saveValues: make(map[string]*SaveValue),
+		matrices:   make(map[string]*Matrix),
+		pending:    make(map[string]bool),
+		blocking:   make(map[string]bool),
+		stopOnce:   &sync.Once{},
+		mu:         &sync.Mutex{},
+	}
+}
+
+// GetSeed - get seed of random streams
+func (p *Pipeline) GetSeed() int64 {
+	return p.seed
+}
+
+// RN - get random stream by number, as RN1..RNn in GPSS. Each stream is
+// seeded from seed of pipeline and number of stream, number less than 1 is
+// treated as 1.
+func (p *Pipeline) RN(n int) *rand.Rand {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	if n < 1 {
+		n = 1
 	}
+	r, ok := p.streams[n]
+	if !ok {
+		r = utils.NewRandom(streamSeed(p.seed, n))
+		p.streams[n] = r
+	}
+	return r
+}
+
+// streamSeed - mix seed of pipeline and number of stream (splitmix64)
+func streamSeed(seed int64, n int) int64 {
+	z := uint64(seed) + uint64(n)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
 }
 
 // Adds an object to the pipeline, a new object is added to the end of the pipeline
@@ -48,9 +121,7 @@ func (p *Pipeline) AddObject(obj ...IBaseObj) *Pipeline {
 		}
 	}
 	for _, item := range obj {
-		item.SetPipeline(p)
-		item.SetID(len(p.objects))
-		p.objects[item.GetName()] = item
+		p.addObject(item)
 	}
 	p.lstObject = obj
 	return p
@@ -91,14 +162,84 @@ func (p *Pipeline) AppendMultiple(obj []IBaseObj, dst ...IBaseObj) {
 // AppendISlice - append slice IBaseObj
 func (p *Pipeline) AppendISlice(obj IBaseObj, dst []IBaseObj) {
 	obj.SetDst(dst...)
-	obj.SetPipeline(p)
+	p.addObject(obj)
+}
+
+// addObject - register object in pipeline, object gets next ID
+func (p *Pipeline) addObject(obj IBaseObj) {
 	obj.SetID(len(p.objects))
 	p.objects[obj.GetName()] = obj
+	p.sorted = nil
+	obj.SetPipeline(p)
+	p.touch(obj.GetName())
+	if p.started {
+		p.startObject(obj)
+	}
+}
+
+// start - schedule first events of objects, it is done once before the first
+// moment of simulation
+func (p *Pipeline) start() {
+	if p.started {
+		return
+	}
+	p.started = true
+	for _, obj := range p.sortedObjects() {
+		p.startObject(obj)
+	}
+}
+
+// startObject - schedule first events of object
+func (p *Pipeline) startObject(obj IBaseObj) {
+	if s, ok := obj.(starter); ok {
+		defer p.recoverError(obj)
+		s.start()
+	}
 }
 
 // Delete object from pipeline
 func (p *Pipeline) Delete(obj IBaseObj) {
 	delete(p.objects, obj.GetName())
+	delete(p.pending, obj.GetName())
+	delete(p.blocking, obj.GetName())
+	p.sorted = nil
+}
+
+// touch - mark object for scanning, object is touched when it gets transact
+// or event
+func (p *Pipeline) touch(name string) {
+	p.pending[name] = true
+}
+
+// objectsOf - get objects from set of names ordered as sortedObjects, names of
+// deleted objects are removed from set
+func (p *Pipeline) objectsOf(names map[string]bool) []IBaseObj {
+	objs := make([]IBaseObj, 0, len(names))
+	for name := range names {
+		obj, ok := p.objects[name]
+		if !ok {
+			delete(names, name)
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	byID.Sort(objs)
+	return objs
+}
+
+// sortedObjects - get objects of pipeline ordered by ID, objects with same ID
+// are ordered by name
+func (p *Pipeline) sortedObjects() []IBaseObj {
+	if p.sorted != nil {
+		return p.sorted
+	}
+	sorted := make([]IBaseObj, 0, len(p.objects))
+	for _, v := range p.objects {
+		sorted = append(sorted, v)
+	}
+	byID.Sort(sorted)
+	p.sorted = sorted
+	return sorted
 }
 
 // PrintObjects - print list of objects ib pipeline
@@ -107,63 +248,261 @@ func (p *Pipeline) PrintObjects() {
 	for k := range p.objects {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	fmt.Println("Pipeline ", p.Name)
 	for _, k := range keys {
 		fmt.Println("Key:", k, "Value:", reflect.TypeOf(p.objects[k]))
 	}
 }
 
-// Start simulation
-func (p *Pipeline) Start(value int) {
+// Schedule - schedule handling of transaction by object after delay. If
+// transaction is nil, object will be woken up. Negative delay is treated as zero.
+func (p *Pipeline) Schedule(obj IBaseObj, transact *Transaction, delay int) *Event {
+	if delay < 0 {
+		delay = 0
+	}
+	e := &Event{Time: p.ModelTime + delay, Obj: obj, Transact: transact, index: -1}
+	p.events.Schedule(e)
+	return e
+}
+
+// Cancel - remove scheduled event from future events chain
+func (p *Pipeline) Cancel(e *Event) {
+	p.events.Cancel(e)
+}
+
+// TerminateWhen - add termination condition, simulation stops when any
+// condition returns true. Conditions are checked after each moment of events.
+func (p *Pipeline) TerminateWhen(cond ...func(p *Pipeline) bool) *Pipeline {
+	p.terminate = append(p.terminate, cond...)
+	return p
+}
+
+// Fail - stop simulation with model error, only first error is saved
+func (p *Pipeline) Fail(err error) {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Err - get model error
+func (p *Pipeline) Err() error {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	return p.err
+}
+
+// recoverError - recover panic in object and stop simulation with model error
+func (p *Pipeline) recoverError(obj IBaseObj) {
+	if r := recover(); r != nil {
+		if err, ok := r.(error); ok {
+			p.Fail(fmt.Errorf("object %q at model time %d: %w", obj.GetName(), p.ModelTime, err))
+			return
+		}
+		p.Fail(fmt.Errorf("object %q at model time %d: %v", obj.GetName(), p.ModelTime, r))
+	}
+}
+
+// handleEvent - handle event of future events chain
+func (p *Pipeline) handleEvent(e *Event) {
+	defer p.recoverError(e.Obj)
+	e.Obj.HandleEvent(e)
+}
+
+// handleEvents - handle all events whose time has come, objects are scanned
+// after each event
+func (p *Pipeline) handleEvents() {
+	for _, e := range p.events.PopDue(p.ModelTime) {
+		p.handleEvent(e)
+		if p.Err() != nil {
+			return
+		}
+		p.touch(e.Obj.GetName())
+		p.scanObjects()
+		if p.Err() != nil {
+			return
+		}
+	}
+}
+
+// scanObjects - give blocked transactions a chance to move. Only objects,
+// which got transacts or events since last scan, and objects, which still hold
+// blocked transacts, are scanned. Touched objects without blocked transactions
+// API are scanned one by one in order of ID. Then blocked transactions are tried
+// in order of priority (higher first), object ID and order of object. Blocked
+// transactions are collected again after each move, because moving of
+// transaction may release a busy object.
+func (p *Pipeline) scanObjects() {
+	for _, o := range p.objectsOf(p.pending) {
+		delete(p.pending, o.GetName())
+		if _, ok := o.(IBlocking); ok {
+			p.blocking[o.GetName()] = true
+			continue
+		}
+		p.scanObject(o)
+		if p.Err() != nil {
+			return
+		}
+	}
+	for p.releaseBlocked() {
+		if p.Err() != nil {
+			return
+		}
+	}
+}
+
+// scanObject - scan one object
+func (p *Pipeline) scanObject(obj IBaseObj) {
 	var wg sync.WaitGroup
+	defer p.recoverError(obj)
+	wg.Add(1)
+	obj.HandleTransacts(&wg)
+	wg.Wait()
+}
+
+// blockedTransact is a blocked transact of object
+type blockedTransact struct {
+	obj      IBaseObj
+	transact *Transaction
+}
+
+// releaseBlocked - try to move blocked transactions, returns true if any
+// transaction was moved. Objects which got transacts during previous moves
+// join the blocking objects, objects without blocked transacts leave them.
+func (p *Pipeline) releaseBlocked() bool {
+	for name := range p.pending {
+		if _, ok := p.objects[name].(IBlocking); ok {
+			p.blocking[name] = true
+			delete(p.pending, name)
+		}
+	}
+	var blocked []blockedTransact
+	for _, o := range p.objectsOf(p.blocking) {
+		transacts := o.(IBlocking).GetBlocked()
+		if len(transacts) == 0 {
+			delete(p.blocking, o.GetName())
+			continue
+		}
+		for _, t := range transacts {
+			blocked = append(blocked, blockedTransact{obj: o, transact: t})
+		}
+	}
+	sort.SliceStable(blocked, func(i, j int) bool {
+		return blocked[i].transact.GetPriority() > blocked[j].transact.GetPriority()
+	})
+	for _, b := range blocked {
+		if p.release(b) {
+			return true
+		}
+		if p.Err() != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// release - try to move blocked transaction
+func (p *Pipeline) release(b blockedTransact) bool {
+	defer p.recoverError(b.obj)
+	return b.obj.(IBlocking).Release(b.transact)
+}
+
+// isTerminated - check termination conditions, panic in condition stops
+// simulation with model error
+func (p *Pipeline) isTerminated() (terminated bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.Fail(fmt.Errorf("termination condition at model time %d: %v", p.ModelTime, r))
+			terminated = true
+		}
+	}()
+	for _, f := range p.terminate {
+		if f(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStopped - check that simulation was stopped
+func (p *Pipeline) isStopped() bool {
+	select {
+	case <-p.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run simulation synchronously. Run blocks until model time reaches until, a
+// termination condition fires, simulation is stopped or the context is
+// cancelled. The model time jumps from one event of the future events chain to
+// the next, objects are scanned only at the moments of events. Run returns the
+// collected statistics and model error, for example a panic in handler of
+// object.
+func (p *Pipeline) Run(ctx context.Context, until int) (*Result, error) {
+	defer p.Stop()
+	p.SimTime = until
+	p.start()
+	for !p.isStopped() {
+		if err := ctx.Err(); err != nil {
+			return p.Result(), err
+		}
+		if err := p.Err(); err != nil {
+			return p.Result(), err
+		}
+		utils.Log.Trace.Println("ModelTime ", p.ModelTime)
+		p.handleEvents()
+		if err := p.Err(); err != nil {
+			return p.Result(), err
+		}
+		if p.isTerminated() {
+			break
+		}
+		next, ok := p.events.NextTime()
+		if !ok || next >= until {
+			p.ModelTime = until
+			break
+		}
+		p.ModelTime = next
+	}
+	return p.Result(), p.Err()
+}
 
-	p.SimTime = value
+// Start simulation in goroutine, it is a wrapper of Run. After the end of
+// simulation Done is closed and handlers of done event are called.
+func (p *Pipeline) Start(value int) {
 	go func() {
-		for {
-			select {
-			case <-p.Done:
-				for _, f := range p.doneHndl {
-					f(p)
-				}
-				return
-			default:
-				utils.Log.Trace.Println("ModelTime ", p.ModelTime)
-				wg.Add(len(p.objects))
-				for _, o := range p.objects {
-					o.HandleTransacts(&wg)
-				}
-				wg.Wait()
-				p.ModelTime++
-				if p.ModelTime == value {
-					p.Stop()
-				}
-			}
+		if _, err := p.Run(context.Background(), value); err != nil &&
+			!errors.Is(err, context.Canceled) {
+			utils.Log.Error.Println(err)
+		}
+		for _, f := range p.doneHndl {
+			f(p)
 		}
 	}()
 }
 
 // Stop simulation
 func (p *Pipeline) Stop() {
-	close(p.Done)
+	p.stopOnce.Do(func() {
+		close(p.Done)
+	})
 }
 
 // Report - print report about work of pipeline
 func (p *Pipeline) Report() {
 	fmt.Println("Pipeline name \"", p.Name, "\"")
 	fmt.Println("Simulation time", p.ModelTime)
-	sortedObjects := make([]IBaseObj, 0, len(p.objects))
-	for _, v := range p.objects {
-		sortedObjects = append(sortedObjects, v)
-	}
-
-	id := func(p1, p2 IBaseObj) bool {
-		return p1.GetID() < p2.GetID()
-	}
-
-	By(id).Sort(sortedObjects)
-	for _, v := range sortedObjects {
+	for _, v := range p.sortedObjects() {
 		v.Report()
 	}
+	for _, t := range p.sortedTables() {
+		t.Report()
+	}
+	p.reportSaveValues()
 }
 
 // GetObjByName get object from pipeline by name
@@ -174,6 +513,14 @@ func (p *Pipeline) GetObjByName(name string) IBaseObj {
 // By is a signature for sort
 type By func(p1, p2 IBaseObj) bool
 
+// byID - order objects by ID, objects with same ID are ordered by name
+var byID = By(func(p1, p2 IBaseObj) bool {
+	if p1.GetID() != p2.GetID() {
+		return p1.GetID() < p2.GetID()
+	}
+	return p1.GetName() < p2.GetName()
+})
+
 // Sort - sort object in pipeline
 func (by By) Sort(objects []IBaseObj) {
 	objs := &objectSorter{
@@ -0,0 +1,178 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+func TestPipeline_RN(t *testing.T) {
+	pipe1 := NewPipelineWithSeed("pipe1", 42)
+	pipe2 := NewPipelineWithSeed("pipe2", 42)
+	if pipe1.RN(1) == pipe1.RN(2) {
+		t.Error("Random streams RN1 and RN2 must be different")
+	}
+	if pipe1.RN(0) != pipe1.RN(1) {
+		t.Error("Random stream RN0 must be RN1")
+	}
+	for i := 0; i < 10; i++ {
+		v1, v2 := pipe1.RN(3).Int(), pipe2.RN(3).Int()
+		if v1 != v2 {
+			t.Fatal("Random stream RN3, expected", v1, "got", v2)
+		}
+	}
+}
+
+func TestPipeline_SameSeedSameResult(t *testing.T) {
+	run := func(seed int64) float64 {
+		pipe := NewPipelineWithSeed("pipe", seed)
+		hole := NewHole("hole")
+		pipe.AddObject(NewGenerator("gen", 10, 5, 0, 0, nil)).
+			AddObject(NewAdvance("adv", 30, 20)).
+			AddObject(hole)
+		pipe.Start(1000)
+		<-pipe.Done
+		return hole.sumLife
+	}
+	if r1, r2 := run(7), run(7); r1 != r2 {
+		t.Error("Sum life for same seed, expected", r1, "got", r2)
+	}
+}
+
+func TestPipeline_Run(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(NewHole("hole"))
+	res, err := pipe.TerminateWhen(func(p *Pipeline) bool {
+		return p.ModelTime >= 50
+	}).Run(context.Background(), 1000)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if res.ModelTime != 50 {
+		t.Error("Model time, expected", 50, "got", res.ModelTime)
+	}
+	if killed := res.Object("hole").Stats["killed"]; killed != 5 {
+		t.Error("Killed transacts, expected", 5, "got", killed)
+	}
+}
+
+func TestPipeline_RunError(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	hndl := func(obj *Check, transact *Transaction) bool {
+		return transact.GetStringParameter("missing") == ""
+	}
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(NewCheck("check", hndl, nil)).
+		AddObject(NewHole("hole"))
+	res, err := pipe.Run(context.Background(), 1000)
+	if err == nil {
+		t.Fatal("Run error, expected error, got", nil)
+	}
+	if res.ModelTime != 10 {
+		t.Error("Model time, expected", 10, "got", res.ModelTime)
+	}
+}
+
+func TestPipeline_RunCancel(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(NewHole("hole"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pipe.Run(ctx, 1000); err != context.Canceled {
+		t.Error("Run error, expected", context.Canceled, "got", err)
+	}
+}
+
+func TestPipeline_DeterministicOrder(t *testing.T) {
+	run := func() *Result {
+		pipe := NewPipelineWithSeed("pipe", 3)
+		queue := NewQueue("queue")
+		pipe.AddObject(NewGenerator("gen1", 5, 4, 0, 0, nil), NewGenerator("gen2", 5, 4, 0, 0, nil)).
+			AddObject(queue).
+			AddObject(NewFacility("fac1", 8, 3), NewFacility("fac2", 8, 3)).
+			AddObject(NewSplit("split", 2, 1, nil))
+		q1, q2 := NewQueue("q1"), NewQueue("q2")
+		pipe.AddObject(q1, q2)
+		f1, f2 := NewFacility("f1", 3, 2), NewFacility("f2", 3, 2)
+		q1.LinkObject(f1)
+		q2.LinkObject(f2)
+		agg := NewAggregate("agg")
+		f1.LinkObject(agg)
+		f2.LinkObject(agg)
+		agg.LinkObject(NewHole("hole"))
+		res, err := pipe.Run(context.Background(), 2000)
+		if err != nil {
+			t.Fatal("Run error, expected", nil, "got", err)
+		}
+		return res
+	}
+	res1 := run()
+	for i := 0; i < 5; i++ {
+		res2 := run()
+		for j, obj := range res1.Objects {
+			for k, v := range obj.Stats {
+				if res2.Objects[j].Stats[k] != v {
+					t.Fatal(obj.Name, k, "expected", v, "got", res2.Objects[j].Stats[k])
+				}
+			}
+		}
+	}
+}
+
+func TestPipeline_Distributions(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 0, 0, 0, 0, nil)
+	gen.Distribution = distributions.NewExponential(10)
+	adv := NewAdvance("adv", 0, 0)
+	adv.Distribution = distributions.NewConstant(7)
+	hole := NewHole("hole")
+	pipe.AddObject(gen).AddObject(adv).AddObject(hole)
+	if _, err := pipe.Run(context.Background(), 10000); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// About 1000 transacts with exponential inter generation time
+	if hole.cntTransact < 900 || hole.cntTransact > 1100 {
+		t.Error("Killed transacts, expected about", 1000, "got", hole.cntTransact)
+	}
+	if hole.sumAdvance != 7*hole.cntTransact {
+		t.Error("Sum advance, expected", 7*hole.cntTransact, "got", hole.sumAdvance)
+	}
+}
+
+// idleQueue counts requests of blocked transacts
+type idleQueue struct {
+	*Queue
+	calls int
+}
+
+func (obj *idleQueue) GetBlocked() []*Transaction {
+	obj.calls++
+	return obj.Queue.GetBlocked()
+}
+
+func TestPipeline_ScanOnlyTouchedObjects(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	idle := &idleQueue{Queue: NewQueue("idle")}
+	hole := NewHole("hole")
+	pipe.Append(idle, hole)
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(NewAdvance("adv", 5, 0)).
+		AddObject(hole)
+	res, err := pipe.Run(context.Background(), 1000)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if killed := res.Object("hole").Stats["killed"]; killed != 99 {
+		t.Error("Killed transacts, expected", 99, "got", killed)
+	}
+	if idle.calls != 1 {
+		t.Error("Requests of blocked transacts of idle queue, expected", 1, "got", idle.calls)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+)
+
+// Priority sets priority of Active Transaction
+type Priority struct {
+	BaseObj
+	Value       int     // New priority of transaction
+	sumTransact float64 // Counter of transacts
+}
+
+// NewPriority creates new Priority.
+// name - name of object; priority - new priority of transaction, greater
+// value is higher priority
+func NewPriority(name string, priority int) *Priority {
+	obj := &Priority{Value: priority}
+	obj.name = name
+	return obj
+}
+
+// AppendTransact append transact to object, priority is set before transact
+// is sent to destination
+func (obj *Priority) AppendTransact(transact *Transaction) bool {
+	obj.BaseObj.AppendTransact(transact)
+	oldPriority := transact.GetPriority()
+	transact.SetPriority(obj.Value)
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			obj.sumTransact++
+			return true
+		}
+	}
+	transact.SetPriority(oldPriority)
+	return false
+}
+
+// Report - print report about object
+func (obj *Priority) Report() {
+	obj.BaseObj.Report()
+	fmt.Printf("Priority %d\tNumber entries %.2f\n\n", obj.Value, obj.sumTransact)
+}
+
+// Stats - get statistics of object
+func (obj *Priority) Stats() map[string]float64 {
+	return map[string]float64{
+		"entries": obj.sumTransact,
+	}
+}
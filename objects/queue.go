@@ -5,24 +5,98 @@
 package objects
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+
+	"github.com/soldatov-s/go-gpss/distributions"
 )
 
+// ErrNoAbandonDst - queue with patience has not destination for reneged
+// transacts
+var ErrNoAbandonDst = errors.New("queue with patience has not abandon destination")
+
 // IQueue implements Queue interface
 type IQueue interface {
 	IsObjectAfterMeEmpty(transact *Transaction) bool // Check that after queue exist empty object
 	GetLength() int                                  // Get queue length
 }
 
+// QueueDiscipline defines order in which transactions leave queue
+type QueueDiscipline int
+
+const (
+	// QueuePriority - transacts with higher priority first, FIFO within a
+	// priority, it is default discipline
+	QueuePriority QueueDiscipline = iota
+	// QueueFIFO - first in, first out, priority is ignored
+	QueueFIFO
+	// QueueLIFO - last in, first out
+	QueueLIFO
+	// QueueRandom - service in random order (SIRO)
+	QueueRandom
+	// QueueCustom - transact selected by Less function first, FIFO for equal
+	// transacts
+	QueueCustom
+)
+
+// QueueLessFunc is a comparator signature for QueueCustom discipline, it
+// returns true if transact a must leave queue before transact b
+type QueueLessFunc func(a, b *Transaction) bool
+
+// ByParameter - get comparator for QueueCustom discipline, transacts with
+// smaller value of numeric parameter leave queue first, for example shortest
+// processing time or earliest due date
+func ByParameter(name string) QueueLessFunc {
+	value := func(t *Transaction) float64 {
+		return GetOr(t, name, 0.0)
+	}
+	return func(a, b *Transaction) bool {
+		return value(a) < value(b)
+	}
+}
+
+// BalkingFunc is a function signature for probabilistic balking, it returns
+// probability that arriving transact refuses to join queue of given length
+type BalkingFunc func(length int) float64
+
+// LinearBalking - get balking function, probability of balking is 0 for
+// queue shorter than min and grows linearly up to 1 for queue of max length
+func LinearBalking(min, max int) BalkingFunc {
+	return func(length int) float64 {
+		if length < min {
+			return 0
+		}
+		if length >= max {
+			return 1
+		}
+		return float64(length-min+1) / float64(max-min+1)
+	}
+}
+
 // Queue of transaction
 type Queue struct {
 	BaseObj
-	sumTimequeue   float64 // Sum all transact queue time
-	sumZeroEntries float64 // Sum zero entrise
-	sumEntries     float64 // Sum all entries
-	maxContent     int     // Max content in queue
-	sumContent     float64 // Sum content in queue
+	Discipline     QueueDiscipline            // Order of leaving queue
+	Less           QueueLessFunc              // Comparator for QueueCustom discipline
+	Stream         int                        // Number of random stream for QueueRandom, balking and patience, RN1 by default
+	Capacity       int                        // Max number of waiting transacts, 0 - unlimited
+	Balking        BalkingFunc                // Probability of balking by queue length
+	OverflowDst    IBaseObj                   // Destination of balked transacts, transact is refused if nil
+	Patience       distributions.Distribution // Time which transact waits before reneging, transact waits forever if nil
+	AbandonDst     IBaseObj                   // Destination of reneged transacts, it is required for Patience
+	selected       *Transaction               // Transact selected randomly for QueueRandom
+	cntBalked      float64                    // Counter of balked transacts
+	cntReneged     float64                    // Counter of reneged transacts
+	events         map[int]*Event             // Scheduled end of patience for each transact
+	impatient      map[int]bool               // Transacts which ran out of patience and wait for AbandonDst
+	sumTimequeue   float64                    // Sum all transact queue time
+	sumZeroEntries float64                    // Sum zero entrise
+	sumEntries     float64                    // Sum all entries
+	maxContent     int                        // Max content in queue
+	content        timeWeighted               // Content of queue, weighted by time
+	waits          sampleStats                // Waiting times of transacts
+	timeOfInput    map[int]int                // Model time of input for each transact in queue
 }
 
 // NewQueue creates new Queue.
@@ -30,13 +104,36 @@ type Queue struct {
 func NewQueue(name string) *Queue {
 	obj := &Queue{}
 	obj.BaseObj.Init(name)
+	obj.timeOfInput = make(map[int]int)
+	obj.events = make(map[int]*Event)
+	obj.impatient = make(map[int]bool)
 	return obj
 }
 
-// HandleTransact handle transact
-func (obj *Queue) HandleTransact(transact *Transaction) {
-	transact.InqQueueTime()
+// start - check configuration of queue when simulation starts
+func (obj *Queue) start() {
+	if obj.Patience != nil && obj.AbandonDst == nil {
+		obj.Pipe.Fail(fmt.Errorf("%w: queue %q", ErrNoAbandonDst, obj.name))
+	}
+}
+
+// HandleTransact handle transact, it tries to send transact from queue to
+// next object, returns true if transact leaved queue
+func (obj *Queue) HandleTransact(transact *Transaction) bool {
 	transact.PrintInfo()
+	timequeue := obj.Pipe.ModelTime - obj.timeOfInput[transact.GetID()]
+	transact.AddQueueTime(timequeue)
+	if !obj.IsObjectAfterMeEmpty(transact) {
+		transact.AddQueueTime(-timequeue)
+		return false
+	}
+	return true
+}
+
+// updateContent - totalize content of queue, it is called after each change
+// of content
+func (obj *Queue) updateContent() {
+	obj.content.set(obj.Pipe.ModelTime, float64(obj.tb.Len()))
 }
 
 // IsObjectAfterMeEmpty check that after queue exist empty object
@@ -54,46 +151,218 @@ func (obj *Queue) GetLength() int {
 	return obj.tb.Len()
 }
 
-// HandleTransacts handle transacts in goroutine
-func (obj *Queue) HandleTransacts(wg *sync.WaitGroup) {
-	go func() {
-		defer wg.Done()
-		// Queue not empty
-		if obj.tb.Len() > 0 {
-			tr := obj.tb.First()
-			for tr != nil {
-				if obj.IsObjectAfterMeEmpty(tr.transact) {
-					obj.sumTimequeue += float64(tr.transact.GetQueueTime())
-					obj.tb.Pop()
-					tr = obj.tb.First()
-				} else {
-					break
-				}
+// averageContent - get average content of queue, weighted by time
+func (obj *Queue) averageContent() float64 {
+	return obj.content.average(obj.Pipe.ModelTime)
+}
+
+// averageTime - get average time in queue of entries, time of transacts
+// which are still waiting is counted up to model time
+func (obj *Queue) averageTime(entries float64) float64 {
+	return ratio(obj.content.area(obj.Pipe.ModelTime), entries)
+}
+
+// head - get transact which leaves queue next by discipline, nil if queue is
+// empty
+func (obj *Queue) head() *Transaction {
+	first := obj.tb.First()
+	if first == nil {
+		return nil
+	}
+	switch obj.Discipline {
+	case QueueLIFO:
+		return obj.tb.Last().transact
+	case QueueRandom:
+		if obj.selected == nil || obj.tb.Item(obj.selected.GetID()) == nil {
+			items := obj.tb.List()
+			obj.selected = items[obj.Pipe.RN(obj.Stream).Intn(len(items))].transact
+		}
+		return obj.selected
+	case QueueCustom:
+		if obj.Less == nil {
+			break
+		}
+		head := first.transact
+		for _, tr := range obj.tb.List()[1:] {
+			if obj.Less(tr.transact, head) {
+				head = tr.transact
 			}
 		}
-		transacts := obj.tb.Items()
-		for _, tr := range transacts {
-			obj.HandleTransact(tr.transact)
+		return head
+	}
+	// Transacts are placed by priority for QueuePriority
+	return first.transact
+}
+
+// GetBlocked - get transact from head of queue and transacts which ran out
+// of patience
+func (obj *Queue) GetBlocked() []*Transaction {
+	head := obj.head()
+	if head == nil {
+		return nil
+	}
+	blocked := []*Transaction{head}
+	if len(obj.impatient) == 0 {
+		return blocked
+	}
+	for _, item := range obj.tb.List() {
+		if item.transact != head && obj.impatient[item.transact.GetID()] {
+			blocked = append(blocked, item.transact)
 		}
-		obj.sumContent += float64(obj.tb.Len())
-	}()
+	}
+	return blocked
+}
+
+// Release - try to send transact from head of queue to next object, transact
+// which ran out of patience tries to leave queue to AbandonDst
+func (obj *Queue) Release(transact *Transaction) bool {
+	if obj.head() != transact || !obj.HandleTransact(transact) {
+		if obj.impatient[transact.GetID()] {
+			return obj.renege(transact)
+		}
+		return false
+	}
+	obj.sumTimequeue += float64(transact.GetQueueTime())
+	obj.waits.add(float64(transact.GetQueueTime()))
+	if transact.GetQueueTime() == 0 {
+		obj.sumZeroEntries++
+	}
+	obj.leave(transact)
+	return true
+}
+
+// leave - remove waiting transact from queue
+func (obj *Queue) leave(transact *Transaction) {
+	if e := obj.events[transact.GetID()]; e != nil {
+		obj.Pipe.Cancel(e)
+		delete(obj.events, transact.GetID())
+	}
+	delete(obj.timeOfInput, transact.GetID())
+	delete(obj.impatient, transact.GetID())
+	obj.tb.Remove(transact)
+	obj.updateContent()
+}
+
+// HandleEvent handle the end of patience of transact, transact leaves queue
+// to AbandonDst. If AbandonDst refuses transact, transact is blocked and tries
+// to leave queue each time objects are scanned, or until it is served.
+func (obj *Queue) HandleEvent(e *Event) {
+	transact := e.Transact
+	if obj.events[transact.GetID()] != e {
+		// Transact already left queue
+		return
+	}
+	delete(obj.events, transact.GetID())
+	obj.impatient[transact.GetID()] = true
+	obj.renege(transact)
+}
+
+// renege - send transact which ran out of patience to AbandonDst, returns
+// false if AbandonDst refuses transact
+func (obj *Queue) renege(transact *Transaction) bool {
+	if obj.AbandonDst == nil {
+		return false
+	}
+	timequeue := obj.Pipe.ModelTime - obj.timeOfInput[transact.GetID()]
+	transact.AddQueueTime(timequeue)
+	if !obj.AbandonDst.AppendTransact(transact) {
+		transact.AddQueueTime(-timequeue)
+		return false
+	}
+	obj.sumTimequeue += float64(timequeue)
+	obj.waits.add(float64(timequeue))
+	obj.cntReneged++
+	obj.leave(transact)
+	return true
+}
+
+// balking is a balking of arriving transact, it is kept on refused transact
+// while transact retries
+type balking struct {
+	drawn   bool // Probability of balking is drawn
+	balk    bool // Drawn decision
+	counted bool // Balked transact is counted
+}
+
+// balk - check that arriving transact refuses to join queue, because queue
+// is full or by probability of balking. Probability is drawn once for
+// transact, refused transact keeps its decision when it retries.
+func (obj *Queue) balk(state *balking) bool {
+	if obj.Capacity > 0 && obj.tb.Len() >= obj.Capacity {
+		return true
+	}
+	if obj.Balking == nil {
+		return false
+	}
+	if !state.drawn {
+		state.drawn = true
+		state.balk = obj.Pipe.RN(obj.Stream).Float64() < obj.Balking(obj.tb.Len())
+	}
+	return state.balk
 }
 
-// AppendTransact append transact to object
+// HandleTransacts handle transacts, tries to send transacts from head of queue
+func (obj *Queue) HandleTransacts(wg *sync.WaitGroup) {
+	defer wg.Done()
+	head := obj.head()
+	for head != nil && obj.Release(head) {
+		head = obj.head()
+	}
+}
+
+// AppendTransact append transact to object. Transact passes the queue at once
+// if queue is empty and next object is free, otherwise transact waits in queue.
+// For QueuePriority transacts with higher priority are placed before transacts
+// with lower priority, transacts with same priority are placed in order of
+// arrival, for another disciplines transacts are placed in order of arrival.
+// Balked transact is sent to OverflowDst, it is refused if OverflowDst is nil
+// or busy. Balked transact is counted once, even if it is refused and retries
+// from the same holder.
 func (obj *Queue) AppendTransact(transact *Transaction) bool {
+	state := &balking{}
+	if v, ok := transact.getDecision(obj.name); ok {
+		state = v.(*balking)
+	}
+	if obj.balk(state) {
+		if !state.counted {
+			state.counted = true
+			obj.cntBalked++
+		}
+		if obj.OverflowDst == nil || !obj.OverflowDst.AppendTransact(transact) {
+			transact.setDecision(obj.name, state)
+			return false
+		}
+		transact.clearDecision(obj.name)
+		return true
+	}
+	transact.clearDecision(obj.name)
 	obj.BaseObj.AppendTransact(transact)
 	transact.SetHolder(obj.name)
-	if !obj.IsObjectAfterMeEmpty(transact) {
-		transact.ResetQueueTime()
+	transact.ResetQueueTime()
+	obj.sumEntries++
+	if obj.tb.Len() == 0 && obj.IsObjectAfterMeEmpty(transact) {
+		obj.sumZeroEntries++
+		obj.waits.add(0)
+		return true
+	}
+	obj.timeOfInput[transact.GetID()] = obj.Pipe.ModelTime
+	if obj.Discipline == QueuePriority {
+		obj.tb.PushByPriority(transact)
+	} else {
 		obj.tb.Push(transact)
-		transact.InqQueueTime()
-		if obj.maxContent < obj.tb.Len() {
-			obj.maxContent = obj.tb.Len()
+	}
+	obj.updateContent()
+	obj.selected = nil
+	if obj.Patience != nil {
+		patience := sampleInt(obj.Patience, obj.Pipe.RN(obj.Stream), transact)
+		if patience < 0 {
+			patience = 0
 		}
-	} else {
-		obj.sumZeroEntries++
+		obj.events[transact.GetID()] = obj.Pipe.Schedule(obj, transact, patience)
+	}
+	if obj.maxContent < obj.tb.Len() {
+		obj.maxContent = obj.tb.Len()
 	}
-	obj.sumEntries++
 	return true
 }
 
@@ -103,9 +372,28 @@ func (obj *Queue) Report() {
 	fmt.Printf("Max content \t%d\tTotal entries \t%2.f\tZero entries \t%2.f\tPersent zero entries \t%.2f%%\n",
 		obj.maxContent, obj.sumEntries, obj.sumZeroEntries, 100*obj.sumZeroEntries/obj.sumEntries)
 	fmt.Printf("Current contents \t%d\tAverage content \t%.2f\tAverage time/trans \t%.2f\n", obj.tb.Len(),
-		obj.sumContent/float64(obj.Pipe.SimTime), obj.sumTimequeue/obj.sumEntries)
+		obj.averageContent(), obj.averageTime(obj.sumEntries))
 	if obj.sumEntries-obj.sumZeroEntries > 0 {
-		fmt.Printf("Average time/trans without zero entries \t%.2f\n", obj.sumTimequeue/(obj.sumEntries-obj.sumZeroEntries))
+		fmt.Printf("Average time/trans without zero entries \t%.2f\n", obj.averageTime(obj.sumEntries-obj.sumZeroEntries))
+	}
+	fmt.Printf("Std dev time/trans \t%.2f\tPercentiles of time 50%% \t%.2f\t90%% \t%.2f\t99%% \t%.2f\n",
+		obj.waits.stdDev(), obj.waits.percentile(50), obj.waits.percentile(90), obj.waits.percentile(99))
+	if obj.cntBalked > 0 || obj.cntReneged > 0 {
+		fmt.Printf("Balked \t%2.f\tReneged \t%2.f\n", obj.cntBalked, obj.cntReneged)
 	}
 	fmt.Println()
 }
+
+// Stats - get statistics of object
+func (obj *Queue) Stats() map[string]float64 {
+	return statsSamples(map[string]float64{
+		"max_content":     float64(obj.maxContent),
+		"entries":         obj.sumEntries,
+		"zero_entries":    obj.sumZeroEntries,
+		"current_content": float64(obj.tb.Len()),
+		"average_content": obj.averageContent(),
+		"average_time":    obj.averageTime(obj.sumEntries),
+		"balked":          obj.cntBalked,
+		"reneged":         obj.cntReneged,
+	}, &obj.waits, "time")
+}
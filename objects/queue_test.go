@@ -0,0 +1,292 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/soldatov-s/go-gpss/distributions"
+)
+
+func TestQueue_AppendTransactByPriority(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(queue, facility)
+	pipe.Append(facility)
+	facility.AppendTransact(NewTransaction(pipe))
+	priorities := []int{0, 5, 0, 5, 1}
+	transacts := make([]*Transaction, len(priorities))
+	for i, p := range priorities {
+		transacts[i] = NewTransaction(pipe)
+		transacts[i].SetPriority(p)
+		queue.AppendTransact(transacts[i])
+	}
+	expected := []*Transaction{transacts[1], transacts[3], transacts[4], transacts[0], transacts[2]}
+	for i, item := range queue.tb.List() {
+		if item.transact != expected[i] {
+			t.Error("Transact in queue at position", i, "expected", expected[i].GetID(), "got", item.transact.GetID())
+		}
+	}
+}
+
+func TestPipeline_PriorityCompetition(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	lowQ, highQ := NewQueue("low queue"), NewQueue("high queue")
+	facility := NewFacility("facility", 10, 0)
+	hole := NewHole("hole")
+	pipe.Append(lowQ, facility)
+	pipe.Append(highQ, facility)
+	pipe.Append(facility, hole)
+	pipe.Append(hole)
+	facility.AppendTransact(NewTransaction(pipe))
+	low, high := NewTransaction(pipe), NewTransaction(pipe)
+	high.SetPriority(5)
+	lowQ.AppendTransact(low)
+	highQ.AppendTransact(high)
+	if _, err := pipe.Run(context.Background(), 11); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if facility.HoldedTransactID != high.GetID() {
+		t.Error("Transact in facility, expected", high.GetID(), "got", facility.HoldedTransactID)
+	}
+}
+
+func TestQueue_Discipline(t *testing.T) {
+	sizes := []int{3, 1, 4, 2}
+	tests := []struct {
+		discipline QueueDiscipline
+		less       QueueLessFunc
+		expected   []int
+	}{
+		{QueuePriority, nil, []int{2, 0, 1, 3}},
+		{QueueFIFO, nil, []int{0, 1, 2, 3}},
+		{QueueLIFO, nil, []int{3, 2, 1, 0}},
+		{QueueCustom, ByParameter("size"), []int{1, 3, 0, 2}},
+		{QueueRandom, nil, nil},
+	}
+	for _, tt := range tests {
+		pipe := NewPipelineWithSeed("pipe", 1)
+		queue := NewQueue("queue")
+		queue.Discipline = tt.discipline
+		queue.Less = tt.less
+		facility := NewFacility("facility", 1, 0)
+		var order []*Transaction
+		check := NewCheck("check", func(obj *Check, transact *Transaction) bool {
+			order = append(order, transact)
+			return true
+		}, nil)
+		pipe.AddObject(queue).AddObject(facility).AddObject(check).AddObject(NewHole("hole"))
+		facility.AppendTransact(NewTransaction(pipe))
+		transacts := make([]*Transaction, len(sizes))
+		for i, size := range sizes {
+			transacts[i] = NewTransaction(pipe)
+			transacts[i].SetParameter("size", size)
+			if i == 2 {
+				transacts[i].SetPriority(5)
+			}
+			queue.AppendTransact(transacts[i])
+		}
+		res, err := pipe.Run(context.Background(), 10)
+		if err != nil {
+			t.Fatal("Run error, expected", nil, "got", err)
+		}
+		if len(order) != len(sizes)+1 {
+			t.Fatal("Discipline", tt.discipline, "served transacts, expected", len(sizes)+1, "got", len(order))
+		}
+		for i, idx := range tt.expected {
+			if order[i+1] != transacts[idx] {
+				t.Error("Discipline", tt.discipline, "position", i, "expected", transacts[idx].GetID(),
+					"got", order[i+1].GetID())
+			}
+		}
+		// Transacts wait 1, 2, 3 and 4 ticks for any discipline
+		stats := res.Object("queue").Stats
+		if stats["entries"] != 4 || stats["average_time"] != 2.5 || stats["current_content"] != 0 {
+			t.Error("Discipline", tt.discipline, "queue stats, expected", 4, 2.5, 0, "got",
+				stats["entries"], stats["average_time"], stats["current_content"])
+		}
+	}
+}
+
+func TestQueue_Capacity(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	queue.Capacity = 2
+	overflow := NewHole("overflow")
+	queue.OverflowDst = overflow
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(queue, facility)
+	pipe.Append(facility)
+	facility.AppendTransact(NewTransaction(pipe))
+	for i := 0; i < 5; i++ {
+		if !queue.AppendTransact(NewTransaction(pipe)) {
+			t.Error("Transact", i, "refused, expected sent to overflow")
+		}
+	}
+	if queue.GetLength() != 2 || overflow.cntTransact != 3 || queue.cntBalked != 3 {
+		t.Error("Queue length and balked, expected", 2, 3, "got", queue.GetLength(), queue.cntBalked)
+	}
+	queue.OverflowDst = nil
+	if queue.AppendTransact(NewTransaction(pipe)) {
+		t.Error("Transact accepted by full queue without overflow destination")
+	}
+	if queue.cntBalked != 4 {
+		t.Error("Balked, expected", 4, "got", queue.cntBalked)
+	}
+}
+
+func TestQueue_BalkingAndReneging(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 2, 0, 0, 0, nil)
+	queue := NewQueue("queue")
+	queue.Balking = LinearBalking(3, 10)
+	queue.Patience = distributions.NewConstant(15)
+	balked, reneged := NewHole("balked"), NewHole("reneged")
+	queue.OverflowDst = balked
+	queue.AbandonDst = reneged
+	facility := NewFacility("facility", 3, 0)
+	served := NewHole("served")
+	pipe.Append(gen, queue)
+	pipe.Append(queue, facility)
+	pipe.Append(facility, served)
+	pipe.Append(served)
+	res, err := pipe.Run(context.Background(), 3000)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	stats := res.Object("queue").Stats
+	if stats["balked"] == 0 || stats["balked"] != balked.cntTransact {
+		t.Error("Balked, expected", balked.cntTransact, "got", stats["balked"])
+	}
+	if stats["reneged"] == 0 || stats["reneged"] != reneged.cntTransact {
+		t.Error("Reneged, expected", reneged.cntTransact, "got", stats["reneged"])
+	}
+	if reneged.sumLife != 15*reneged.cntTransact {
+		t.Error("Life of reneged transacts, expected", 15*reneged.cntTransact, "got", reneged.sumLife)
+	}
+	if stats["max_content"] > 10 {
+		t.Error("Max content, expected not more than", 10, "got", stats["max_content"])
+	}
+}
+
+func TestQueue_RenegeRetry(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	queue.Patience = distributions.NewConstant(5)
+	server, desk := NewFacility("server", 100, 0), NewFacility("desk", 20, 0)
+	queue.AbandonDst = desk
+	hole := NewHole("hole")
+	pipe.Append(queue, server)
+	pipe.Append(server, hole)
+	pipe.Append(desk, hole)
+	pipe.Append(hole)
+	server.AppendTransact(NewTransaction(pipe))
+	desk.AppendTransact(NewTransaction(pipe))
+	queue.AppendTransact(NewTransaction(pipe))
+	if _, err := pipe.Run(context.Background(), 30); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// Desk is busy at the end of patience, transact reneges when desk is free
+	if queue.cntReneged != 1 || queue.GetLength() != 0 || desk.cntTransact != 2 {
+		t.Error("Reneged and desk entries, expected", 1, 2, "got", queue.cntReneged, desk.cntTransact)
+	}
+	if queue.sumTimequeue != 20 {
+		t.Error("Time in queue, expected", 20, "got", queue.sumTimequeue)
+	}
+}
+
+func TestQueue_PatienceWithoutAbandonDst(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	queue.Patience = distributions.NewConstant(5)
+	pipe.AddObject(NewGenerator("gen", 1, 0, 0, 0, nil)).
+		AddObject(queue).
+		AddObject(NewFacility("facility", 10, 0)).
+		AddObject(NewHole("hole"))
+	if _, err := pipe.Run(context.Background(), 100); !errors.Is(err, ErrNoAbandonDst) {
+		t.Error("Run error, expected", ErrNoAbandonDst, "got", err)
+	}
+}
+
+func TestQueue_BalkingOnce(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	queue.Balking = func(length int) float64 { return 0.5 }
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(queue, facility)
+	pipe.Append(facility)
+	facility.AppendTransact(NewTransaction(pipe))
+	refused := 0
+	for i := 0; i < 100; i++ {
+		transact := NewTransaction(pipe)
+		if queue.AppendTransact(transact) {
+			continue
+		}
+		refused++
+		// Balked transact retries and keeps its decision
+		for retry := 0; retry < 5; retry++ {
+			if queue.AppendTransact(transact) {
+				t.Fatal("Balked transact", transact.GetID(), "joined queue on retry")
+			}
+		}
+	}
+	if refused < 35 || refused > 65 || queue.cntBalked != float64(refused) {
+		t.Error("Balked, expected", refused, "got", queue.cntBalked)
+	}
+}
+
+func TestQueue_AverageTimeOfWaiting(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 2, nil)
+	queue := NewQueue("queue")
+	facility := NewFacility("facility", 1000, 0)
+	hole := NewHole("hole")
+	pipe.Append(gen, queue)
+	pipe.Append(queue, facility)
+	pipe.Append(facility, hole)
+	pipe.Append(hole)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// The second transact is still waiting, it waits from 10 to 100
+	stats := queue.Stats()
+	if stats["entries"] != 2 || stats["average_time"] != 45 {
+		t.Error("Entries and average time, expected", 2, 45, "got", stats["entries"], stats["average_time"])
+	}
+	if qt := pipe.MustAttr("QT", "queue"); qt != stats["average_time"] {
+		t.Error("QT, expected", stats["average_time"], "got", qt)
+	}
+}
+
+func TestQueue_BalkingOfMovedTransact(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	queue.Balking = func(length int) float64 { return 1 }
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(queue, facility)
+	pipe.Append(facility)
+	transact := NewTransaction(pipe)
+	transact.SetHolder("transfer")
+	for retry := 0; retry < 3; retry++ {
+		if queue.AppendTransact(transact) {
+			t.Fatal("Balked transact joined queue on retry", retry)
+		}
+	}
+	if queue.cntBalked != 1 {
+		t.Error("Balked on retries, expected", 1, "got", queue.cntBalked)
+	}
+	// Transact went to another block, decision is not kept by queue
+	transact.SetHolder("other")
+	if _, ok := transact.getDecision(queue.name); ok {
+		t.Error("Decision of moved transact, expected", false, "got", ok)
+	}
+	queue.AppendTransact(transact)
+	if queue.cntBalked != 2 {
+		t.Error("Balked after move, expected", 2, "got", queue.cntBalked)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+// IStats implements interface of object with statistics
+type IStats interface {
+	Stats() map[string]float64 // Get statistics of object
+}
+
+// ObjectResult is statistics of object at the end of simulation
+type ObjectResult struct {
+	ID    int                // Object ID
+	Name  string             // Object name
+	Type  string             // Object type, for example "Queue"
+	Stats map[string]float64 // Statistics of object, empty if object has not statistics
+}
+
+// TableResult is frequency distribution of table at the end of simulation
+type TableResult struct {
+	Name        string             // Table name
+	Stats       map[string]float64 // Statistics of table
+	Frequencies []float64          // Frequencies of bins
+}
+
+// Result is result of simulation
+type Result struct {
+	Name       string                 // Pipeline name
+	ModelTime  int                    // Model time at the end of simulation
+	SimTime    int                    // Simulation time
+	Seed       int64                  // Seed of random streams
+	Objects    []ObjectResult         // Statistics of objects ordered by object ID
+	Tables     []TableResult          // Statistics of tables ordered by name
+	SaveValues map[string]interface{} // Values of savevalues by name
+	Matrices   map[string][][]float64 // Values of matrices by name
+}
+
+// Object - get statistics of object by name, nil if object not found
+func (r *Result) Object(name string) *ObjectResult {
+	for i := range r.Objects {
+		if r.Objects[i].Name == name {
+			return &r.Objects[i]
+		}
+	}
+	return nil
+}
+
+// Table - get statistics of table by name, nil if table not found
+func (r *Result) Table(name string) *TableResult {
+	for i := range r.Tables {
+		if r.Tables[i].Name == name {
+			return &r.Tables[i]
+		}
+	}
+	return nil
+}
+
+// Result - collect statistics of all objects of pipeline
+func (p *Pipeline) Result() *Result {
+	res := &Result{
+		Name:       p.Name,
+		ModelTime:  p.ModelTime,
+		SimTime:    p.SimTime,
+		Seed:       p.seed,
+		SaveValues: p.SaveValues(),
+		Matrices:   p.Matrices(),
+	}
+	for _, v := range p.sortedObjects() {
+		objRes := ObjectResult{
+			ID:    v.GetID(),
+			Name:  v.GetName(),
+			Type:  typeName(v),
+			Stats: make(map[string]float64),
+		}
+		if st, ok := v.(IStats); ok {
+			objRes.Stats = st.Stats()
+		}
+		res.Objects = append(res.Objects, objRes)
+	}
+	for _, t := range p.sortedTables() {
+		res.Tables = append(res.Tables, TableResult{Name: t.Name, Stats: t.Stats(), Frequencies: t.Frequencies()})
+	}
+	return res
+}
+
+// ratio - divide a by b, returns zero if b is zero
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
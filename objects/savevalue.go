@@ -0,0 +1,350 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SaveValue is a GPSS SAVEVALUE entity, a global variable shared by all
+// transactions, for example total revenue or last order number. SaveValues
+// are registered in pipeline by name, Savevalue block sets or increments
+// them. Value can be read from any block or handler, access is synchronized.
+type SaveValue struct {
+	Name  string      // SaveValue name
+	value interface{} // Current value
+	mu    sync.Mutex
+}
+
+// NewSaveValue creates new SaveValue.
+// name - name of savevalue; value - initial value
+func NewSaveValue(name string, value interface{}) *SaveValue {
+	return &SaveValue{Name: name, value: value}
+}
+
+// Get - get value
+func (sv *SaveValue) Get() interface{} {
+	defer sv.mu.Unlock()
+	sv.mu.Lock()
+	return sv.value
+}
+
+// GetFloat - get numeric value, 0 if value is not a number
+func (sv *SaveValue) GetFloat() float64 {
+	v, _ := toFloat64(sv.Get())
+	return v
+}
+
+// Set - set value
+func (sv *SaveValue) Set(value interface{}) {
+	defer sv.mu.Unlock()
+	sv.mu.Lock()
+	sv.value = value
+}
+
+// Add - increment numeric value by delta and get new value. Int value stays
+// int if delta is a whole number, otherwise value becomes float64. Not
+// numeric value is replaced by delta.
+func (sv *SaveValue) Add(delta float64) float64 {
+	defer sv.mu.Unlock()
+	sv.mu.Lock()
+	v, _ := toFloat64(sv.value)
+	sum := v + delta
+	if _, ok := sv.value.(int); ok && delta == math.Trunc(delta) {
+		sv.value = int(sum)
+	} else {
+		sv.value = sum
+	}
+	return sum
+}
+
+// Matrix is a GPSS MATRIX entity, a two-dimensional table of numbers, for
+// example a demand matrix between locations, registered in pipeline and set
+// by Msavevalue block. Rows and columns are numbered from 0.
+type Matrix struct {
+	Name   string      // Matrix name
+	Rows   int         // Number of rows
+	Cols   int         // Number of columns
+	values [][]float64 // Values by row and column
+	mu     sync.Mutex
+}
+
+// NewMatrix creates new Matrix filled by zeros.
+// name - name of matrix; rows - number of rows; cols - number of columns
+func NewMatrix(name string, rows, cols int) *Matrix {
+	m := &Matrix{Name: name, Rows: rows, Cols: cols, values: make([][]float64, rows)}
+	for i := range m.values {
+		m.values[i] = make([]float64, cols)
+	}
+	return m
+}
+
+// check - panic if cell is out of matrix
+func (m *Matrix) check(row, col int) {
+	if row < 0 || row >= m.Rows || col < 0 || col >= m.Cols {
+		panic(fmt.Sprintf("cell (%d, %d) is out of matrix %q %dx%d", row, col, m.Name, m.Rows, m.Cols))
+	}
+}
+
+// Get - get value of cell
+func (m *Matrix) Get(row, col int) float64 {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+	m.check(row, col)
+	return m.values[row][col]
+}
+
+// Set - set value of cell
+func (m *Matrix) Set(row, col int, value float64) {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+	m.check(row, col)
+	m.values[row][col] = value
+}
+
+// Add - increment value of cell by delta and get new value
+func (m *Matrix) Add(row, col int, delta float64) float64 {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+	m.check(row, col)
+	m.values[row][col] += delta
+	return m.values[row][col]
+}
+
+// Values - get copy of values by row and column
+func (m *Matrix) Values() [][]float64 {
+	defer m.mu.Unlock()
+	m.mu.Lock()
+	values := make([][]float64, m.Rows)
+	for i := range values {
+		values[i] = append([]float64(nil), m.values[i]...)
+	}
+	return values
+}
+
+// toFloat64 - convert numeric value to float64, returns false if value is not
+// a number
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case time.Duration:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// AddSaveValue - register savevalues in pipeline
+func (p *Pipeline) AddSaveValue(saveValues ...*SaveValue) *Pipeline {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	for _, sv := range saveValues {
+		p.saveValues[sv.Name] = sv
+	}
+	return p
+}
+
+// GetSaveValue - get savevalue by name, nil if savevalue not found
+func (p *Pipeline) GetSaveValue(name string) *SaveValue {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	return p.saveValues[name]
+}
+
+// AddMatrix - register matrices in pipeline
+func (p *Pipeline) AddMatrix(matrices ...*Matrix) *Pipeline {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	for _, m := range matrices {
+		p.matrices[m.Name] = m
+	}
+	return p
+}
+
+// GetMatrix - get matrix by name, nil if matrix not found
+func (p *Pipeline) GetMatrix(name string) *Matrix {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	return p.matrices[name]
+}
+
+// reportSaveValues - print values of savevalues and matrices ordered by name
+func (p *Pipeline) reportSaveValues() {
+	saveValues := p.SaveValues()
+	names := make([]string, 0, len(saveValues))
+	for name := range saveValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("SaveValue \"%s\"\t%v\n", name, saveValues[name])
+	}
+	matrices := p.Matrices()
+	names = names[:0]
+	for name := range matrices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("Matrix \"%s\"\n", name)
+		for _, row := range matrices[name] {
+			for _, v := range row {
+				fmt.Printf("%.2f\t", v)
+			}
+			fmt.Println()
+		}
+	}
+	if len(saveValues)+len(matrices) > 0 {
+		fmt.Println()
+	}
+}
+
+// SaveValues - get values of all savevalues by name
+func (p *Pipeline) SaveValues() map[string]interface{} {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	values := make(map[string]interface{}, len(p.saveValues))
+	for name, sv := range p.saveValues {
+		values[name] = sv.Get()
+	}
+	return values
+}
+
+// Matrices - get values of all matrices by name
+func (p *Pipeline) Matrices() map[string][][]float64 {
+	defer p.mu.Unlock()
+	p.mu.Lock()
+	values := make(map[string][][]float64, len(p.matrices))
+	for name, m := range p.matrices {
+		values[name] = m.Values()
+	}
+	return values
+}
+
+// SaveValueFunc is a function signature for value of Savevalue block
+type SaveValueFunc func(transact *Transaction) interface{}
+
+// Savevalue block sets or increments SaveValue
+type Savevalue struct {
+	BaseObj
+	saveValue *SaveValue
+	Value     SaveValueFunc // Value for set or increment, it must be a number for increment
+	Increment bool          // SaveValue is incremented by value instead of set
+}
+
+// NewSavevalue creates new Savevalue.
+// name - name of object; saveValue - savevalue for modification; increment -
+// savevalue is incremented by value instead of set; value - value
+func NewSavevalue(name string, saveValue *SaveValue, increment bool, value SaveValueFunc) *Savevalue {
+	obj := &Savevalue{saveValue: saveValue, Increment: increment, Value: value}
+	obj.BaseObj.Init(name)
+	return obj
+}
+
+// SetPipeline - set pipeline of Savevalue, savevalue is added to pipeline if
+// it is not added yet
+func (obj *Savevalue) SetPipeline(pipe *Pipeline) {
+	obj.BaseObj.SetPipeline(pipe)
+	if pipe.GetSaveValue(obj.saveValue.Name) == nil {
+		pipe.AddSaveValue(obj.saveValue)
+	}
+}
+
+// AppendTransact append transact to object, savevalue is modified before
+// transact goes to destination, so destination sees new value. Old value is
+// restored if destination refuses transact.
+func (obj *Savevalue) AppendTransact(transact *Transaction) bool {
+	obj.BaseObj.AppendTransact(transact)
+	transact.SetHolder(obj.name)
+	old := obj.saveValue.Get()
+	value := obj.Value(transact)
+	if obj.Increment {
+		delta, ok := toFloat64(value)
+		if !ok {
+			panic(fmt.Sprintf("increment savevalue %q by not a number %v", obj.saveValue.Name, value))
+		}
+		obj.saveValue.Add(delta)
+	} else {
+		obj.saveValue.Set(value)
+	}
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			return true
+		}
+	}
+	obj.saveValue.Set(old)
+	return false
+}
+
+// Report - print report about object
+func (obj *Savevalue) Report() {}
+
+// MatrixCellFunc is a function signature for cell of Msavevalue block
+type MatrixCellFunc func(transact *Transaction) (row, col int)
+
+// MatrixValueFunc is a function signature for value of Msavevalue block
+type MatrixValueFunc func(transact *Transaction) float64
+
+// Msavevalue block sets or increments cell of Matrix
+type Msavevalue struct {
+	BaseObj
+	matrix    *Matrix
+	Cell      MatrixCellFunc  // Cell for modification
+	Value     MatrixValueFunc // Value for set or increment
+	Increment bool            // Cell is incremented by value instead of set
+}
+
+// NewMsavevalue creates new Msavevalue.
+// name - name of object; matrix - matrix for modification; increment - cell
+// is incremented by value instead of set; cell - cell; value - value
+func NewMsavevalue(name string, matrix *Matrix, increment bool, cell MatrixCellFunc, value MatrixValueFunc) *Msavevalue {
+	obj := &Msavevalue{matrix: matrix, Increment: increment, Cell: cell, Value: value}
+	obj.BaseObj.Init(name)
+	return obj
+}
+
+// SetPipeline - set pipeline of Msavevalue, matrix is added to pipeline if it
+// is not added yet
+func (obj *Msavevalue) SetPipeline(pipe *Pipeline) {
+	obj.BaseObj.SetPipeline(pipe)
+	if pipe.GetMatrix(obj.matrix.Name) == nil {
+		pipe.AddMatrix(obj.matrix)
+	}
+}
+
+// AppendTransact append transact to object, cell of matrix is modified before
+// transact goes to destination, so destination sees new value. Old value is
+// restored if destination refuses transact.
+func (obj *Msavevalue) AppendTransact(transact *Transaction) bool {
+	obj.BaseObj.AppendTransact(transact)
+	transact.SetHolder(obj.name)
+	row, col := obj.Cell(transact)
+	old := obj.matrix.Get(row, col)
+	if obj.Increment {
+		obj.matrix.Add(row, col, obj.Value(transact))
+	} else {
+		obj.matrix.Set(row, col, obj.Value(transact))
+	}
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			return true
+		}
+	}
+	obj.matrix.Set(row, col, old)
+	return false
+}
+
+// Report - print report about object
+func (obj *Msavevalue) Report() {}
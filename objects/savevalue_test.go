@@ -0,0 +1,116 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSaveValue(t *testing.T) {
+	sv := NewSaveValue("sv", 5)
+	if sv.Add(2.5) != 7.5 || sv.GetFloat() != 7.5 {
+		t.Error("Incremented value, expected", 7.5, "got", sv.Get())
+	}
+	counter := NewSaveValue("counter", 5)
+	if counter.Add(2) != 7 || counter.Get() != 7 {
+		t.Error("Incremented int value, expected int", 7, "got", counter.Get())
+	}
+	sv.Set("route")
+	if sv.Get() != "route" || sv.GetFloat() != 0 {
+		t.Error("Value, expected", "route", "got", sv.Get())
+	}
+	m := NewMatrix("m", 2, 3)
+	m.Set(1, 2, 4)
+	if m.Add(1, 2, 1) != 5 || m.Get(1, 2) != 5 || m.Get(0, 0) != 0 {
+		t.Error("Value of cell, expected", 5, "got", m.Values())
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Cell out of matrix, expected panic")
+		}
+	}()
+	m.Get(2, 0)
+}
+
+func TestSavevalue_Blocks(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 4, nil)
+	gen.Parameters = []ParameterTemplate{ConstParameter("price", 2.5), ConstParameter("from", 1)}
+	revenue := NewSaveValue("revenue", 0)
+	last := NewSaveValue("last", nil)
+	demand := NewMatrix("demand", 2, 2)
+	addRevenue := NewSavevalue("add revenue", revenue, true, func(transact *Transaction) interface{} {
+		return transact.GetParameter("price")
+	})
+	setLast := NewSavevalue("set last", last, false, func(transact *Transaction) interface{} {
+		return transact.GetID()
+	})
+	addDemand := NewMsavevalue("add demand", demand, true, func(transact *Transaction) (int, int) {
+		return transact.GetIntParameter("from"), 0
+	}, func(transact *Transaction) float64 { return 1 })
+	hole := NewHole("hole")
+	pipe.Append(gen, addRevenue)
+	pipe.Append(addRevenue, setLast)
+	pipe.Append(setLast, addDemand)
+	pipe.Append(addDemand, hole)
+	pipe.Append(hole)
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if pipe.GetSaveValue("revenue") != revenue || res.SaveValues["revenue"] != 10.0 {
+		t.Error("Revenue, expected", 10.0, "got", res.SaveValues["revenue"])
+	}
+	if res.SaveValues["last"] != 4 {
+		t.Error("Last transact, expected", 4, "got", res.SaveValues["last"])
+	}
+	if res.Matrices["demand"][1][0] != 4 {
+		t.Error("Demand, expected", 4, "got", res.Matrices["demand"])
+	}
+}
+
+func TestSavevalue_BeforeRouting(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	stock := NewSaveValue("stock", 10)
+	cells := NewMatrix("cells", 1, 1)
+	take := NewSavevalue("take", stock, true, func(transact *Transaction) interface{} { return -1 })
+	mark := NewMsavevalue("mark", cells, false, func(transact *Transaction) (int, int) { return 0, 0 },
+		func(transact *Transaction) float64 { return float64(transact.GetID()) })
+	var seen []float64
+	check := NewCheck("check", func(obj *Check, transact *Transaction) bool {
+		seen = append(seen, stock.GetFloat(), cells.Get(0, 0))
+		return true
+	}, nil)
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(take, mark)
+	pipe.Append(mark, check)
+	pipe.Append(check, facility)
+	pipe.Append(facility)
+	first, second := NewTransaction(pipe), NewTransaction(pipe)
+	if !take.AppendTransact(first) || take.AppendTransact(second) {
+		t.Fatal("Facility must accept the first transact and refuse the second")
+	}
+	// Check sees new values, values are restored for refused transact
+	if seen[0] != 9 || seen[1] != float64(first.GetID()) {
+		t.Error("Values seen by next block, expected", 9, first.GetID(), "got", seen[:2])
+	}
+	if stock.Get() != 9 || cells.Get(0, 0) != float64(first.GetID()) {
+		t.Error("Values after refusal, expected", 9, first.GetID(), "got", stock.Get(), cells.Get(0, 0))
+	}
+}
+
+func TestCount_GetValue(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	inc, dec := NewCount("count", 2, -1)
+	hole := NewHole("hole")
+	pipe.Append(inc, dec)
+	pipe.Append(dec, hole)
+	pipe.Append(hole)
+	inc.AppendTransact(NewTransaction(pipe))
+	if inc.GetValue() != 1 || dec.GetValue() != 1 {
+		t.Error("Count value, expected", 1, "got", inc.GetValue())
+	}
+}
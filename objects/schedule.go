@@ -0,0 +1,110 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// RateWindow is a window of rate schedule, it lasts until start of next window
+type RateWindow struct {
+	Start int     // Start of window, relative to start of period
+	Rate  float64 // Mean number of arrivals per tick
+}
+
+// RateFunc is a function signature for arbitrary arrival rate, it returns
+// mean number of arrivals per tick at model time
+type RateFunc func(modelTime float64) float64
+
+// RateSchedule is a time-varying arrival rate of Generator, arrivals are
+// sampled as non-homogeneous Poisson process by thinning
+type RateSchedule struct {
+	Windows []RateWindow // Piecewise constant rates ordered by Start
+	Period  int          // Period of repeating windows, for example 1440 for day in minutes, 0 - windows are not repeated
+	Rate    RateFunc     // Arbitrary rate, it is used instead of windows if set
+	MaxRate float64      // Upper bound of arbitrary rate
+}
+
+// NewRateSchedule creates new RateSchedule with piecewise constant rates.
+// period - period of repeating windows, 0 if windows are not repeated;
+// windows - windows of schedule
+func NewRateSchedule(period int, windows ...RateWindow) *RateSchedule {
+	s := &RateSchedule{Windows: windows, Period: period}
+	sort.SliceStable(s.Windows, func(i, j int) bool { return s.Windows[i].Start < s.Windows[j].Start })
+	for _, w := range s.Windows {
+		s.MaxRate = math.Max(s.MaxRate, w.Rate)
+	}
+	return s
+}
+
+// NewRateFunction creates new RateSchedule with arbitrary rate.
+// rate - function of rate; maxRate - upper bound of rate
+func NewRateFunction(rate RateFunc, maxRate float64) *RateSchedule {
+	return &RateSchedule{Rate: rate, MaxRate: maxRate}
+}
+
+// window - get index of window for model time, -1 if time is before the first
+// window of not repeated schedule
+func (s *RateSchedule) window(modelTime float64) int {
+	if len(s.Windows) == 0 {
+		return -1
+	}
+	t := modelTime
+	if s.Period > 0 {
+		t = math.Mod(t, float64(s.Period))
+	}
+	idx := sort.Search(len(s.Windows), func(i int) bool { return float64(s.Windows[i].Start) > t }) - 1
+	if idx < 0 && s.Period > 0 {
+		// Last window of previous period
+		return len(s.Windows) - 1
+	}
+	return idx
+}
+
+// RateAt - get arrival rate at model time
+func (s *RateSchedule) RateAt(modelTime float64) float64 {
+	if s.Rate != nil {
+		return math.Max(0, math.Min(s.Rate(modelTime), s.MaxRate))
+	}
+	idx := s.window(modelTime)
+	if idx < 0 {
+		return 0
+	}
+	return s.Windows[idx].Rate
+}
+
+// isOver - is there no arrivals after time?
+func (s *RateSchedule) isOver(t float64) bool {
+	if s.MaxRate <= 0 {
+		return true
+	}
+	if s.Rate != nil || s.Period > 0 {
+		return false
+	}
+	last := len(s.Windows) - 1
+	return last < 0 || (t >= float64(s.Windows[last].Start) && s.Windows[last].Rate == 0)
+}
+
+// maxCandidates - limit of candidates for next arrival without horizon
+const maxCandidates = 1 << 20
+
+// next - get time of next arrival after time t, +Inf if there are no arrivals
+// until horizon. Candidates are drawn with max rate and accepted with
+// probability rate/max rate (thinning). Without horizon (+Inf) search is
+// limited by maxCandidates, so rate falling to zero forever stops arrivals.
+func (s *RateSchedule) next(r *rand.Rand, t, horizon float64) float64 {
+	for i := 0; !s.isOver(t) && t <= horizon; i++ {
+		if math.IsInf(horizon, 1) && i >= maxCandidates {
+			break
+		}
+		t += r.ExpFloat64() / s.MaxRate
+		if t <= horizon && r.Float64()*s.MaxRate < s.RateAt(t) {
+			return t
+		}
+	}
+	return math.Inf(1)
+}
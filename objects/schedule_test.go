@@ -0,0 +1,112 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newScheduleGenerator(schedule *RateSchedule, count int) (*Pipeline, *Generator) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 0, 0, 0, count, nil)
+	gen.Schedule = schedule
+	pipe.AddObject(gen).AddObject(NewHole("hole"))
+	return pipe, gen
+}
+
+func TestGenerator_Schedule(t *testing.T) {
+	// Peak in the first half of period, quiet in the second half
+	pipe, gen := newScheduleGenerator(NewRateSchedule(100,
+		RateWindow{Start: 50, Rate: 0.1}, RateWindow{Start: 0, Rate: 0.5}), 0)
+	res, err := pipe.Run(context.Background(), 10000)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	stats := res.Object("gen").Stats
+	if stats["window_0"] < 2300 || stats["window_0"] > 2700 {
+		t.Error("Arrivals in peak window, expected about", 2500, "got", stats["window_0"])
+	}
+	if stats["window_50"] < 400 || stats["window_50"] > 600 {
+		t.Error("Arrivals in quiet window, expected about", 500, "got", stats["window_50"])
+	}
+	if stats["generated"] != stats["window_0"]+stats["window_50"] {
+		t.Error("Generated, expected", stats["window_0"]+stats["window_50"], "got", stats["generated"])
+	}
+	if gen.cntWindow[-1] != 0 {
+		t.Error("Arrivals out of windows, expected", 0, "got", gen.cntWindow[-1])
+	}
+}
+
+func TestGenerator_ScheduleStops(t *testing.T) {
+	// Not repeated schedule, arrivals only from 10 to 20
+	pipe, gen := newScheduleGenerator(NewRateSchedule(0,
+		RateWindow{Start: 10, Rate: 1}, RateWindow{Start: 20, Rate: 0}), 0)
+	if _, err := pipe.Run(context.Background(), 1000); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if gen.cntWindow[1] != 0 || gen.cntWindow[0] != float64(gen.id-1) {
+		t.Error("Arrivals in windows, expected", gen.id-1, 0, "got", gen.cntWindow[0], gen.cntWindow[1])
+	}
+	if pipe.events.Len() != 0 {
+		t.Error("Scheduled events, expected", 0, "got", pipe.events.Len())
+	}
+}
+
+func TestGenerator_RateFunction(t *testing.T) {
+	// Rate grows from 0 to 1 during 1000 ticks, about 500 arrivals without limit
+	pipe, gen := newScheduleGenerator(NewRateFunction(func(modelTime float64) float64 {
+		return modelTime / 1000
+	}, 1), 300)
+	if _, err := pipe.Run(context.Background(), 1000); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if gen.id-1 != 300 {
+		t.Error("Generated with creation limit, expected", 300, "got", gen.id-1)
+	}
+}
+
+func TestGenerator_RateFunctionStops(t *testing.T) {
+	// Rate falls to zero forever at 100, about 50 arrivals
+	pipe, gen := newScheduleGenerator(NewRateFunction(func(modelTime float64) float64 {
+		if modelTime < 100 {
+			return 0.5
+		}
+		return 0
+	}, 1), 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := pipe.Run(ctx, 100000)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if gen.id-1 < 30 || gen.id-1 > 70 {
+		t.Error("Generated, expected about", 50, "got", gen.id-1)
+	}
+	if res.ModelTime != 100000 {
+		t.Error("Model time, expected", 100000, "got", res.ModelTime)
+	}
+}
+
+func TestGenerator_FieldsAfterAddObject(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 0, 0, 0, 0, nil)
+	hole := NewHole("hole")
+	pipe.AddObject(gen).AddObject(hole)
+	// Schedule is set after generator is added to pipeline
+	gen.Schedule = NewRateSchedule(0, RateWindow{Start: 10, Rate: 0.5}, RateWindow{Start: 30, Rate: 0})
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	stats := res.Object("gen").Stats
+	if stats["generated"] != stats["window_10"] || stats["window_30"] != 0 {
+		t.Error("Generated, expected", stats["window_10"], "got", stats["generated"])
+	}
+	if hole.sumLife != 0 || gen.cntWindow[-1] != 0 {
+		t.Error("Arrivals before schedule, expected", 0, "got", gen.cntWindow[-1])
+	}
+}
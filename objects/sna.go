@@ -0,0 +1,312 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrUnknownSNA - code of SNA is not supported
+	ErrUnknownSNA = errors.New("unknown SNA")
+	// ErrEntityNotFound - entity with name and type of SNA not found
+	ErrEntityNotFound = errors.New("entity not found")
+)
+
+// snaQueue - SNA of Queue and Gate, values are keys of statistics of entity
+// with same value. SNA are read from counters, without statistics.
+var snaQueue = map[string]string{
+	"Q":  "current_content", // Current length
+	"QM": "max_content",     // Max length
+	"QA": "average_content", // Average length, weighted by time
+	"QC": "entries",         // Total entries
+	"QZ": "zero_entries",    // Entries without waiting
+	"QT": "average_time",    // Average time of waiting
+}
+
+// snaFacility - SNA of Facility and InFacility
+var snaFacility = map[string]string{
+	"F":  "current_content", // 1 if facility is busy
+	"FR": "utilization",     // Utilization, percent
+	"FC": "entries",         // Total entries
+	"FT": "average_time",    // Average holding time
+	"FP": "preemptions",     // Number of preemptions
+}
+
+// snaStorage - SNA of Storage
+var snaStorage = map[string]string{
+	"S":  "current_content",   // Units in use
+	"SR": "utilization",       // Utilization, percent
+	"SM": "max_content",       // Max units in use
+	"SA": "average_content",   // Average units in use, weighted by time
+	"SC": "entries",           // Total entered units
+	"ST": "average_time_unit", // Average holding time of unit
+}
+
+// snaOther - SNA of entities and transact, which are not statistics
+var snaOther = map[string]bool{
+	"AC1": false, "C1": false, "M1": false, "PR": false, "XN1": false,
+	"P": true, "X": true, "TB": true, "TC": true, "TD": true, "N": true, "W": true,
+	"FV": true, "FH": true, "R": true, "SE": true, "SF": true, "SV": true,
+}
+
+// isSNA - check that code of SNA is supported
+func isSNA(code string) bool {
+	_, ok := snaOther[code]
+	if !ok {
+		_, ok = snaQueue[code]
+	}
+	if !ok {
+		_, ok = snaFacility[code]
+	}
+	if !ok {
+		_, ok = snaStorage[code]
+	}
+	return ok
+}
+
+// snaNeedsName - check that SNA is read with name of entity or parameter
+func snaNeedsName(code string) bool {
+	needsName, ok := snaOther[code]
+	return !ok || needsName
+}
+
+// Attr - get Standard Numerical Attribute (SNA), current value of entity as
+// Q$name, F$name, S$name, X$name or AC1 in GPSS, by code of SNA and name of
+// entity, for example pipe.Attr("Q", "Visitors queue"). Codes are:
+//   - AC1 - absolute clock, name is ignored;
+//   - N, W - total and current number of transacts in block;
+//   - Q, QM, QA, QC, QZ, QT - current, max and average length, entries, zero
+//     entries and average time of Queue or Gate;
+//   - F, FR, FC, FT, FP, FV, FH - busy (1 or 0), utilization, entries, average
+//     time, preemptions, available (1 or 0) and ID of holder (0 if empty) of
+//     Facility or first part of Bifacility;
+//   - S, R, SR, SM, SA, SC, ST, SE, SF, SV - content, free units, utilization,
+//     max and average content, entries, average time of unit, empty, full and
+//     available (1 or 0) of Storage;
+//   - X - numeric value of SaveValue;
+//   - TB, TC, TD - mean, entries and standard deviation of Table.
+//
+// Returns ErrUnknownSNA if code is not supported and ErrEntityNotFound if
+// entity with name and suitable type is not registered in pipeline.
+func (p *Pipeline) Attr(sna, name string) (float64, error) {
+	switch sna {
+	case "AC1", "C1":
+		return float64(p.ModelTime), nil
+	case "X":
+		sv := p.GetSaveValue(name)
+		if sv == nil {
+			return 0, notFound(sna, name, "savevalue")
+		}
+		return sv.GetFloat(), nil
+	case "TB", "TC", "TD":
+		t := p.GetTable(name)
+		if t == nil {
+			return 0, notFound(sna, name, "table")
+		}
+		switch sna {
+		case "TB":
+			return t.Mean(), nil
+		case "TC":
+			return float64(t.Entries()), nil
+		}
+		return t.StdDev(), nil
+	case "N", "W":
+		obj := p.GetObjByName(name)
+		code := map[string]string{"N": "entries", "W": "current_content"}[sna]
+		switch obj.(type) {
+		case *Queue, *Gate:
+			code = map[string]string{"N": "QC", "W": "Q"}[sna]
+		case *Facility, *InFacility:
+			code = map[string]string{"N": "FC", "W": "F"}[sna]
+		case *Storage:
+			code = map[string]string{"N": "SC", "W": "S"}[sna]
+		default:
+			st, ok := obj.(IStats)
+			if !ok {
+				return 0, notFound(sna, name, "block")
+			}
+			v, ok := st.Stats()[code]
+			if !ok {
+				return 0, fmt.Errorf("%w: %s$%s, %s has not %s", ErrUnknownSNA, sna, name, typeName(obj), code)
+			}
+			return v, nil
+		}
+		return p.Attr(code, name)
+	}
+	obj := p.GetObjByName(name)
+	if _, ok := snaQueue[sna]; ok {
+		switch v := obj.(type) {
+		case *Queue:
+			return queueAttr(sna, v.tb.Len(), v.maxContent, v.averageContent(),
+				v.sumEntries, v.sumZeroEntries, v.averageTime(v.sumEntries)), nil
+		case *Gate:
+			return queueAttr(sna, v.tb.Len(), v.maxContent, v.averageContent(),
+				v.sumEntries, v.sumZeroEntries, v.averageTime()), nil
+		}
+		return 0, notFound(sna, name, "queue")
+	}
+	if _, ok := snaFacility[sna]; ok || sna == "FV" || sna == "FH" {
+		var f IAvailability
+		var holder, content int
+		var busy *timeWeighted
+		var entries, preemptions float64
+		switch v := obj.(type) {
+		case *Facility:
+			f, holder, content, busy, entries, preemptions = v, v.HoldedTransactID, v.tb.Len(), &v.busy, v.cntTransact, v.cntPreempt
+		case *InFacility:
+			f, holder, content, busy, entries, preemptions = v, v.HoldedTransactID, v.tb.Len(), &v.busy, v.cntTransact, v.cntPreempt
+		default:
+			return 0, notFound(sna, name, "facility")
+		}
+		switch sna {
+		case "F":
+			return float64(content), nil
+		case "FR":
+			return 100 * busy.average(p.ModelTime), nil
+		case "FC":
+			return entries, nil
+		case "FT":
+			return ratio(busy.area(p.ModelTime), entries), nil
+		case "FP":
+			return preemptions, nil
+		case "FV":
+			return boolAttr(f.IsAvailable()), nil
+		}
+		if holder < 0 {
+			holder = 0
+		}
+		return float64(holder), nil
+	}
+	if _, ok := snaStorage[sna]; ok || sna == "R" || sna == "SE" || sna == "SF" || sna == "SV" {
+		s, ok := obj.(*Storage)
+		if !ok {
+			return 0, notFound(sna, name, "storage")
+		}
+		switch sna {
+		case "S":
+			return float64(s.content), nil
+		case "SR":
+			return 100 * ratio(s.averageContent(), float64(s.Capacity)), nil
+		case "SM":
+			return float64(s.maxContent), nil
+		case "SA":
+			return s.averageContent(), nil
+		case "SC":
+			return s.cntUnits, nil
+		case "ST":
+			return ratio(s.weightedContent.area(p.ModelTime), s.cntUnits), nil
+		case "R":
+			return float64(s.GetAvailable()), nil
+		case "SE":
+			return boolAttr(s.IsEmpty()), nil
+		case "SF":
+			return boolAttr(s.IsFull()), nil
+		}
+		return boolAttr(s.IsAvailable()), nil
+	}
+	return 0, fmt.Errorf("%w: %s$%s", ErrUnknownSNA, sna, name)
+}
+
+// queueAttr - get SNA of Queue or Gate from counters, percentiles of waiting
+// time are not computed
+func queueAttr(sna string, content, maxContent int, averageContent, entries, zeroEntries, averageTime float64) float64 {
+	switch sna {
+	case "QM":
+		return float64(maxContent)
+	case "QA":
+		return averageContent
+	case "QC":
+		return entries
+	case "QZ":
+		return zeroEntries
+	case "QT":
+		return averageTime
+	}
+	return float64(content)
+}
+
+// MustAttr - get SNA of entity as Attr, it panics if SNA or entity not found.
+// Panic in handler of object stops simulation with model error.
+func (p *Pipeline) MustAttr(sna, name string) float64 {
+	v, err := p.Attr(sna, name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Attr - get SNA of transact:
+//   - P - numeric value of parameter, name is a name of parameter;
+//   - PR - priority;
+//   - M1 - transit time, time since transact was generated up to current
+//     model time;
+//   - XN1 - ID of transact.
+//
+// Another SNA are SNA of entities of pipeline of transact.
+func (t *Transaction) Attr(sna, name string) (float64, error) {
+	switch sna {
+	case "P":
+		v, ok := toFloat64(t.GetParameter(name))
+		if !ok {
+			return 0, fmt.Errorf("%w: P$%s, parameter is %v", ErrEntityNotFound, name, t.GetParameter(name))
+		}
+		return v, nil
+	case "PR":
+		return float64(t.GetPriority()), nil
+	case "M1":
+		return float64(t.GetTransitTime()), nil
+	case "XN1":
+		return float64(t.GetID()), nil
+	}
+	if t.GetPipeline() == nil {
+		return 0, fmt.Errorf("%w: %s$%s, transact has not pipeline", ErrEntityNotFound, sna, name)
+	}
+	return t.GetPipeline().Attr(sna, name)
+}
+
+// GetQueue - get Queue by name
+func (p *Pipeline) GetQueue(name string) (*Queue, error) {
+	if q, ok := p.GetObjByName(name).(*Queue); ok {
+		return q, nil
+	}
+	return nil, notFound("Q", name, "queue")
+}
+
+// GetFacility - get Facility by name
+func (p *Pipeline) GetFacility(name string) (*Facility, error) {
+	if f, ok := p.GetObjByName(name).(*Facility); ok {
+		return f, nil
+	}
+	return nil, notFound("F", name, "facility")
+}
+
+// GetStorage - get Storage by name
+func (p *Pipeline) GetStorage(name string) (*Storage, error) {
+	if s, ok := p.GetObjByName(name).(*Storage); ok {
+		return s, nil
+	}
+	return nil, notFound("S", name, "storage")
+}
+
+// notFound - get error for entity which is not found or has another type
+func notFound(sna, name, kind string) error {
+	return fmt.Errorf("%w: %s$%s, %s %q not found", ErrEntityNotFound, sna, name, kind, name)
+}
+
+// typeName - get name of type of object
+func typeName(obj interface{}) string {
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// boolAttr - convert boolean SNA to 1 or 0
+func boolAttr(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPipeline_Attr(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	facility := NewFacility("facility", 10, 0)
+	storage := NewStorage("storage", 3)
+	enter := NewEnter("enter", storage, 2)
+	pipe.Append(queue, facility)
+	pipe.Append(facility)
+	hole := NewHole("hole")
+	pipe.Append(enter, hole)
+	pipe.Append(hole)
+	pipe.AddSaveValue(NewSaveValue("sv", 7))
+	holder := NewTransaction(pipe)
+	facility.AppendTransact(holder)
+	queue.AppendTransact(NewTransaction(pipe))
+	queue.AppendTransact(NewTransaction(pipe))
+	enter.AppendTransact(NewTransaction(pipe))
+	if _, err := pipe.Run(context.Background(), 5); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	tests := []struct {
+		sna, name string
+		expected  float64
+	}{
+		{"AC1", "", 5},
+		{"Q", "queue", 2},
+		{"QM", "queue", 2},
+		{"QC", "queue", 2},
+		{"N", "queue", 2},
+		{"F", "facility", 1},
+		{"FR", "facility", 100},
+		{"FH", "facility", float64(holder.GetID())},
+		{"FV", "facility", 1},
+		{"S", "storage", 2},
+		{"R", "storage", 1},
+		{"SF", "storage", 0},
+		{"X", "sv", 7},
+	}
+	for _, tt := range tests {
+		v, err := pipe.Attr(tt.sna, tt.name)
+		if err != nil || v != tt.expected {
+			t.Error(tt.sna, tt.name, "expected", tt.expected, "got", v, err)
+		}
+	}
+	if v, err := holder.Attr("XN1", ""); err != nil || v != float64(holder.GetID()) {
+		t.Error("XN1, expected", holder.GetID(), "got", v, err)
+	}
+	if v, err := holder.Attr("Q", "queue"); err != nil || v != 2 {
+		t.Error("Q of transact pipeline, expected", 2, "got", v, err)
+	}
+}
+
+func TestPipeline_AttrErrors(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	pipe.AddObject(NewQueue("queue"))
+	if _, err := pipe.Attr("F", "queue"); !errors.Is(err, ErrEntityNotFound) {
+		t.Error("Facility SNA of queue, expected", ErrEntityNotFound, "got", err)
+	}
+	if _, err := pipe.Attr("Q", "missing"); !errors.Is(err, ErrEntityNotFound) {
+		t.Error("Missing queue, expected", ErrEntityNotFound, "got", err)
+	}
+	if _, err := pipe.Attr("ZZ", "queue"); !errors.Is(err, ErrUnknownSNA) {
+		t.Error("Unknown SNA, expected", ErrUnknownSNA, "got", err)
+	}
+	if _, err := pipe.GetFacility("queue"); err == nil {
+		t.Error("GetFacility of queue, expected error, got", err)
+	}
+}
+
+func TestPipeline_AttrMatchesStats(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	storage := NewStorage("storage", 2)
+	pipe.AddObject(NewGenerator("gen", 3, 2, 0, 0, nil)).
+		AddObject(NewQueue("queue")).
+		AddObject(NewFacility("facility", 4, 2)).
+		AddObject(NewEnter("enter", storage, 1)).
+		AddObject(NewAdvance("advance", 5, 0)).
+		AddObject(NewLeave("leave", storage, 1)).
+		AddObject(NewHole("hole"))
+	res, err := pipe.Run(context.Background(), 500)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	check := func(name string, codes map[string]string) {
+		stats := res.Object(name).Stats
+		for code, key := range codes {
+			if v := pipe.MustAttr(code, name); v != stats[key] {
+				t.Error(code, name, "expected", stats[key], "got", v)
+			}
+		}
+	}
+	check("queue", snaQueue)
+	check("facility", snaFacility)
+	check("storage", snaStorage)
+}
+
+func TestTransaction_AttrM1(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	var transit []float64
+	check := NewCheck("check", func(obj *Check, transact *Transaction) bool {
+		v, err := transact.Attr("M1", "")
+		if err != nil {
+			t.Error("M1, expected", nil, "got", err)
+		}
+		transit = append(transit, v)
+		return true
+	}, nil)
+	pipe.AddObject(NewGenerator("gen", 10, 0, 0, 0, nil)).
+		AddObject(NewAdvance("advance", 4, 0)).
+		AddObject(check).
+		AddObject(NewAdvance("after", 3, 0)).
+		AddObject(NewHole("hole"))
+	if _, err := pipe.Run(context.Background(), 50); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if len(transit) == 0 {
+		t.Fatal("Check is not passed by transacts")
+	}
+	for _, v := range transit {
+		if v != 4 {
+			t.Fatal("M1 of live transact, expected", 4, "got", v)
+		}
+	}
+}
@@ -7,6 +7,7 @@ package objects
 import (
 	"fmt"
 
+	"github.com/soldatov-s/go-gpss/distributions"
 	utils "github.com/soldatov-s/go-gpss/internal"
 )
 
@@ -16,18 +17,22 @@ type HandleSplittingFunc func(obj *Split, transact *Transaction)
 // A Split creates assembly set of sub-transactions of a Transaction
 type Split struct {
 	BaseObj
-	Cntsplit        int                 // Number of related Transactions to be created
-	Modificator     int                 // The count half-range
-	sumSplit        float64             // Counter of sub-transactions
-	sumTransact     float64             // Counter of transactions
-	HandleSplitting HandleSplittingFunc // Function for splitting transaction
+	Cntsplit        int                        // Number of related Transactions to be created
+	Modificator     int                        // The count half-range
+	Distribution    distributions.Distribution // Distribution of count, used instead of Cntsplit and Modificator if set
+	Stream          int                        // Number of random stream, RN1 by default
+	sumSplit        float64                    // Counter of sub-transactions
+	sumTransact     float64                    // Counter of transactions
+	HandleSplitting HandleSplittingFunc        // Function for splitting transaction
 }
 
 // Splitting - default splitting function
 func Splitting(obj *Split, transact *Transaction) {
 	cntsplit := obj.Cntsplit
-	if obj.Modificator > 0 {
-		cntsplit += utils.GetRandom(-obj.Modificator, obj.Modificator)
+	if obj.Distribution != nil {
+		cntsplit = sampleInt(obj.Distribution, obj.Pipe.RN(obj.Stream), transact)
+	} else if obj.Modificator > 0 {
+		cntsplit += utils.GetRandomFrom(obj.Pipe.RN(obj.Stream), -obj.Modificator, obj.Modificator)
 	}
 
 	if cntsplit <= 0 {
@@ -54,7 +59,7 @@ func Splitting(obj *Split, transact *Transaction) {
 		partID := 1
 		for {
 			for _, v := range obj.GetDst() {
-				if !(utils.GetRandomBool() && !dsts[partID-1]) {
+				if !(utils.GetRandomBoolFrom(obj.Pipe.RN(obj.Stream)) && !dsts[partID-1]) {
 					continue
 				}
 				tr := transact.Copy()
@@ -109,3 +114,11 @@ func (obj *Split) Report() {
 	fmt.Printf("Average split %.2f\n", obj.sumSplit/obj.sumTransact)
 	fmt.Println()
 }
+
+// Stats - get statistics of object
+func (obj *Split) Stats() map[string]float64 {
+	return map[string]float64{
+		"average_split": ratio(obj.sumSplit, obj.sumTransact),
+		"entries":       obj.sumTransact,
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// timeWeighted is a time-weighted accumulator of value, for example content
+// of queue or state of facility. It is updated on every change of value, so
+// averages are exact for any moment of model time.
+type timeWeighted struct {
+	value        float64 // Current value
+	sum          float64 // Sum of value, weighted by time
+	timeOfChange int     // Model time of last change of value
+}
+
+// set - totalize value from the last change to model time and set new value
+func (tw *timeWeighted) set(modelTime int, value float64) {
+	tw.sum = tw.area(modelTime)
+	tw.timeOfChange = modelTime
+	tw.value = value
+}
+
+// area - get sum of value, weighted by time, up to model time
+func (tw *timeWeighted) area(modelTime int) float64 {
+	return tw.sum + tw.value*float64(modelTime-tw.timeOfChange)
+}
+
+// average - get average value from start of simulation to model time
+func (tw *timeWeighted) average(modelTime int) float64 {
+	return ratio(tw.area(modelTime), float64(modelTime))
+}
+
+// sampleStats collects samples, for example waiting times of transacts, for
+// standard deviation and percentiles
+type sampleStats struct {
+	values     []float64 // Samples
+	sum        float64   // Sum of samples
+	sumSquares float64   // Sum of squares of samples
+	sorted     bool      // Samples are sorted
+}
+
+// add - add sample
+func (s *sampleStats) add(value float64) {
+	s.values = append(s.values, value)
+	s.sum += value
+	s.sumSquares += value * value
+	s.sorted = false
+}
+
+// remove - remove one sample with value, it undoes add
+func (s *sampleStats) remove(value float64) {
+	for i := len(s.values) - 1; i >= 0; i-- {
+		if s.values[i] == value {
+			s.values = append(s.values[:i], s.values[i+1:]...)
+			s.sum -= value
+			s.sumSquares -= value * value
+			return
+		}
+	}
+}
+
+// mean - get mean of samples
+func (s *sampleStats) mean() float64 {
+	return ratio(s.sum, float64(len(s.values)))
+}
+
+// stdDev - get sample standard deviation
+func (s *sampleStats) stdDev() float64 {
+	n := float64(len(s.values))
+	if n < 2 {
+		return 0
+	}
+	return math.Sqrt(math.Max(0, (s.sumSquares-s.sum*s.sum/n)/(n-1)))
+}
+
+// percentile - get percentile of samples, p is from 0 to 100. Value is
+// interpolated linearly between closest ranks.
+func (s *sampleStats) percentile(p float64) float64 {
+	if len(s.values) == 0 {
+		return 0
+	}
+	if !s.sorted {
+		sort.Float64s(s.values)
+		s.sorted = true
+	}
+	rank := math.Max(0, math.Min(p, 100)) / 100 * float64(len(s.values)-1)
+	lower := int(rank)
+	if lower+1 >= len(s.values) {
+		return s.values[lower]
+	}
+	return s.values[lower] + (rank-float64(lower))*(s.values[lower+1]-s.values[lower])
+}
+
+// statsSamples - add standard deviation and percentiles 50, 90, 95 and 99 of
+// samples to statistics, names of keys end with suffix
+func statsSamples(stats map[string]float64, s *sampleStats, suffix string) map[string]float64 {
+	stats["std_dev_"+suffix] = s.stdDev()
+	for _, p := range []int{50, 90, 95, 99} {
+		stats["p"+strconv.Itoa(p)+"_"+suffix] = s.percentile(float64(p))
+	}
+	return stats
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestSampleStats(t *testing.T) {
+	var s sampleStats
+	for _, v := range []float64{4, 1, 3, 2, 5} {
+		s.add(v)
+	}
+	if s.mean() != 3 {
+		t.Error("Mean, expected", 3, "got", s.mean())
+	}
+	if math.Abs(s.stdDev()-math.Sqrt(2.5)) > 1e-9 {
+		t.Error("Std dev, expected", math.Sqrt(2.5), "got", s.stdDev())
+	}
+	tests := []struct{ p, expected float64 }{{0, 1}, {50, 3}, {90, 4.6}, {100, 5}}
+	for _, tt := range tests {
+		if v := s.percentile(tt.p); math.Abs(v-tt.expected) > 1e-9 {
+			t.Error("Percentile", tt.p, "expected", tt.expected, "got", v)
+		}
+	}
+}
+
+func TestTimeWeighted(t *testing.T) {
+	var tw timeWeighted
+	tw.set(2, 3)
+	tw.set(6, 1)
+	// 0 during 0-2, 3 during 2-6, 1 during 6-10
+	if tw.area(10) != 16 || tw.average(10) != 1.6 {
+		t.Error("Area and average, expected", 16, 1.6, "got", tw.area(10), tw.average(10))
+	}
+}
+
+func TestFacility_UtilizationAtEndOfRun(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	facility := NewFacility("facility", 30, 0)
+	pipe.Append(facility)
+	facility.AppendTransact(NewTransaction(pipe))
+	res, err := pipe.Run(context.Background(), 10)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	// Service runs past the end of run, only time until the end is counted
+	if stats := res.Object("facility").Stats; stats["utilization"] != 100 || stats["average_time"] != 10 {
+		t.Error("Utilization and average time, expected", 100, 10, "got", stats["utilization"], stats["average_time"])
+	}
+}
+
+func TestQueue_WaitingTimes(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	queue := NewQueue("queue")
+	facility := NewFacility("facility", 10, 0)
+	hole := NewHole("hole")
+	pipe.Append(queue, facility)
+	pipe.Append(facility, hole)
+	pipe.Append(hole)
+	// Transacts wait 0, 10, 20 and 30 ticks
+	for i := 0; i < 4; i++ {
+		queue.AppendTransact(NewTransaction(pipe))
+	}
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	stats := res.Object("queue").Stats
+	expected := map[string]float64{
+		"average_time":    15,
+		"average_content": 0.6,
+		"p50_time":        15,
+		"p99_time":        29.7,
+		"std_dev_time":    math.Sqrt(500.0 / 3),
+	}
+	for key, value := range expected {
+		if math.Abs(stats[key]-value) > 1e-9 {
+			t.Error(key, "expected", value, "got", stats[key])
+		}
+	}
+}
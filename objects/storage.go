@@ -0,0 +1,251 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+)
+
+// IStorage implements Storage interface
+type IStorage interface {
+	GetCapacity() int  // Get capacity of storage
+	GetContent() int   // Get number of units in use
+	GetAvailable() int // Get number of free units
+	IsEmpty() bool     // Check that storage is empty
+	IsFull() bool      // Check that storage is full
+}
+
+// Storage is a multi-server entity with capacity, it present in storage and
+// two blocks, Enter takes units of a Storage, Leave releases units of a
+// Storage. Many Enter and Leave blocks can be linked to one Storage.
+type Storage struct {
+	BaseObj
+	availability
+	// Capacity of storage
+	Capacity int
+	// Number of units in use
+	content int
+	// Max number of units in use
+	maxContent int
+	// Units in use, weighted by time
+	weightedContent timeWeighted
+	// For counting the units that go through storage
+	cntUnits float64
+	// For counting the transacts that go through storage
+	cntTransact float64
+}
+
+// Enter takes units of Storage
+type Enter struct {
+	BaseObj
+	// Pointer to storage
+	storage *Storage
+	// Number of units for take
+	Units int
+}
+
+// Leave releases units of Storage
+type Leave struct {
+	BaseObj
+	// Pointer to storage
+	storage *Storage
+	// Number of units for release
+	Units int
+}
+
+// NewStorage creates new Storage.
+// name - name of object; capacity - number of units of storage
+func NewStorage(name string, capacity int) *Storage {
+	obj := &Storage{}
+	obj.BaseObj.Init(name)
+	obj.Capacity = capacity
+	return obj
+}
+
+// NewEnter creates new Enter.
+// name - name of object; storage - storage; units - number of units for take,
+// 1 if units less than 1
+func NewEnter(name string, storage *Storage, units int) *Enter {
+	obj := &Enter{storage: storage, Units: units}
+	obj.name = name
+	if obj.Units < 1 {
+		obj.Units = 1
+	}
+	return obj
+}
+
+// NewLeave creates new Leave.
+// name - name of object; storage - storage; units - number of units for
+// release, 1 if units less than 1
+func NewLeave(name string, storage *Storage, units int) *Leave {
+	obj := &Leave{storage: storage, Units: units}
+	obj.name = name
+	if obj.Units < 1 {
+		obj.Units = 1
+	}
+	return obj
+}
+
+// updateContent - change content of storage and totalize content from the
+// last change to current model time
+func (obj *Storage) updateContent(units int) {
+	obj.content += units
+	obj.weightedContent.set(obj.Pipe.ModelTime, float64(obj.content))
+}
+
+// averageContent - get average content of storage, weighted by time
+func (obj *Storage) averageContent() float64 {
+	return obj.weightedContent.average(obj.Pipe.ModelTime)
+}
+
+// AppendTransact storage is not a block, transacts enter in storage by Enter
+func (obj *Storage) AppendTransact(transact *Transaction) bool {
+	return false
+}
+
+// GetCapacity get capacity of storage
+func (obj *Storage) GetCapacity() int {
+	return obj.Capacity
+}
+
+// GetContent get number of units in use
+func (obj *Storage) GetContent() int {
+	return obj.content
+}
+
+// GetAvailable get number of free units
+func (obj *Storage) GetAvailable() int {
+	return obj.Capacity - obj.content
+}
+
+// IsEmpty check that storage is empty
+func (obj *Storage) IsEmpty() bool {
+	return obj.content == 0
+}
+
+// IsFull check that storage is full
+func (obj *Storage) IsFull() bool {
+	return obj.content >= obj.Capacity
+}
+
+// checkPolicy - check that storage supports policy. Transacts which took
+// units of storage are served by another blocks, so storage supports only
+// BreakdownContinue.
+func (obj *Storage) checkPolicy(policy BreakdownPolicy) error {
+	if policy != BreakdownContinue {
+		return fmt.Errorf("%w: storage %q supports only BreakdownContinue", ErrBreakdownPolicy, obj.name)
+	}
+	return nil
+}
+
+// SetUnavailable - storage becomes unavailable, transacts in storage continue
+// service, new transacts are refused until repair. Another policy stops
+// simulation with model error.
+func (obj *Storage) SetUnavailable(policy BreakdownPolicy, dst IBaseObj) {
+	if err := obj.checkPolicy(policy); err != nil {
+		panic(err)
+	}
+	obj.fail(obj.Pipe.ModelTime)
+}
+
+// SetAvailable - storage becomes available
+func (obj *Storage) SetAvailable() {
+	obj.repair(obj.Pipe.ModelTime)
+}
+
+// Report - print report about object
+func (obj *Storage) Report() {
+	obj.BaseObj.Report()
+	avr := obj.averageContent()
+	fmt.Printf("Capacity %d\tAverage content %.2f\tAverage utilization %.2f%%\tNumber entries %.2f\n",
+		obj.Capacity, avr, 100*ratio(avr, float64(obj.Capacity)), obj.cntUnits)
+	fmt.Printf("Average time/unit %.2f\tCurrent content %d\tMax content %d",
+		ratio(obj.weightedContent.area(obj.Pipe.ModelTime), obj.cntUnits), obj.content, obj.maxContent)
+	obj.reportAvailability(obj.Pipe.ModelTime)
+	fmt.Printf("\n\n")
+}
+
+// Stats - get statistics of object
+func (obj *Storage) Stats() map[string]float64 {
+	avr := obj.averageContent()
+	return obj.statsAvailability(map[string]float64{
+		"capacity":          float64(obj.Capacity),
+		"average_content":   avr,
+		"utilization":       100 * ratio(avr, float64(obj.Capacity)),
+		"entries":           obj.cntUnits,
+		"transacts":         obj.cntTransact,
+		"average_time_unit": ratio(obj.weightedContent.area(obj.Pipe.ModelTime), obj.cntUnits),
+		"current_content":   float64(obj.content),
+		"max_content":       float64(obj.maxContent),
+	}, obj.Pipe.ModelTime)
+}
+
+// SetPipeline - set pipeline of Enter, storage is added to pipeline if it is
+// not added yet
+func (obj *Enter) SetPipeline(pipe *Pipeline) {
+	obj.BaseObj.SetPipeline(pipe)
+	if pipe.GetObjByName(obj.storage.GetName()) == nil {
+		pipe.addObject(obj.storage)
+	}
+}
+
+// AppendTransact append transact to object, transact takes units of storage
+// and goes to destination. Returns false if storage has not enough free units
+// or storage is unavailable.
+func (obj *Enter) AppendTransact(transact *Transaction) bool {
+	if !obj.storage.IsAvailable() || obj.storage.GetAvailable() < obj.Units {
+		// Storage is full
+		return false
+	}
+	obj.BaseObj.AppendTransact(transact)
+	transact.SetHolder(obj.name)
+	obj.storage.updateContent(obj.Units)
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			obj.storage.cntUnits += float64(obj.Units)
+			obj.storage.cntTransact++
+			if obj.storage.maxContent < obj.storage.content {
+				obj.storage.maxContent = obj.storage.content
+			}
+			return true
+		}
+	}
+	obj.storage.updateContent(-obj.Units)
+	return false
+}
+
+// Report - print report about object
+func (obj *Enter) Report() {}
+
+// SetPipeline - set pipeline of Leave, storage is added to pipeline if it is
+// not added yet
+func (obj *Leave) SetPipeline(pipe *Pipeline) {
+	obj.BaseObj.SetPipeline(pipe)
+	if pipe.GetObjByName(obj.storage.GetName()) == nil {
+		pipe.addObject(obj.storage)
+	}
+}
+
+// AppendTransact append transact to object, transact releases units of
+// storage and goes to destination
+func (obj *Leave) AppendTransact(transact *Transaction) bool {
+	if obj.storage.GetContent() < obj.Units {
+		panic(fmt.Sprintf("leave %d units of storage %q with content %d",
+			obj.Units, obj.storage.GetName(), obj.storage.GetContent()))
+	}
+	obj.BaseObj.AppendTransact(transact)
+	transact.SetHolder(obj.name)
+	obj.storage.updateContent(-obj.Units)
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			return true
+		}
+	}
+	obj.storage.updateContent(obj.Units)
+	return false
+}
+
+// Report - print report about object
+func (obj *Leave) Report() {}
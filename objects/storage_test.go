@@ -0,0 +1,38 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStorage_EnterLeave(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	parking := NewStorage("parking", 3)
+	pipe.AddObject(NewGenerator("cars", 0, 0, 0, 6, nil)).
+		AddObject(NewQueue("queue")).
+		AddObject(NewEnter("enter", parking, 1)).
+		AddObject(NewAdvance("parked", 10, 0)).
+		AddObject(NewLeave("leave", parking, 1)).
+		AddObject(NewHole("out"))
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	stats := res.Object("parking").Stats
+	if stats["max_content"] != 3 {
+		t.Error("Max content, expected", 3, "got", stats["max_content"])
+	}
+	if stats["average_time_unit"] != 10 {
+		t.Error("Average time/unit, expected", 10, "got", stats["average_time_unit"])
+	}
+	if parking.GetAvailable() != 3 {
+		t.Error("Available units, expected", 3, "got", parking.GetAvailable())
+	}
+	if maxContent := res.Object("queue").Stats["max_content"]; maxContent != 3 {
+		t.Error("Max content of queue, expected", 3, "got", maxContent)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// TableArgFunc is a function signature for tabulated value of transact
+type TableArgFunc func(transact *Transaction) float64
+
+// TransitTime - tabulated value is time since transact was generated
+func TransitTime(transact *Transaction) float64 {
+	return float64(transact.GetTransitTime())
+}
+
+// QueueResidence - tabulated value is time which transact waited in the last
+// queue
+func QueueResidence(transact *Transaction) float64 {
+	return float64(transact.GetQueueTime())
+}
+
+// ParameterValue - get tabulated value from numeric parameter of transact
+func ParameterValue(name string) TableArgFunc {
+	return func(transact *Transaction) float64 {
+		return GetOr(transact, name, 0.0)
+	}
+}
+
+// Table is a GPSS TABLE entity, it collects frequency distribution of values,
+// for example transit time of transactions, into bins of equal width. Tables
+// are registered in pipeline by name, values are recorded by Tabulate block.
+type Table struct {
+	Name        string       // Table name
+	Argument    TableArgFunc // Tabulated value, used by Tabulate without own argument
+	LowerLimit  float64      // Upper limit of the first bin, it holds all values not greater than it
+	Width       float64      // Width of bins
+	Count       int          // Number of bins, the last bin holds all values greater than previous bins
+	frequencies []float64    // Frequencies of bins
+	values      sampleStats  // Tabulated values, weighted values are repeated
+}
+
+// NewTable creates new Table.
+// name - name of table; argument - tabulated value; lowerLimit - upper limit
+// of the first bin; width - width of bins; count - number of bins
+func NewTable(name string, argument TableArgFunc, lowerLimit, width float64, count int) *Table {
+	if count < 2 {
+		count = 2
+	}
+	return &Table{
+		Name:        name,
+		Argument:    argument,
+		LowerLimit:  lowerLimit,
+		Width:       width,
+		Count:       count,
+		frequencies: make([]float64, count),
+	}
+}
+
+// bin - get index of bin for value
+func (t *Table) bin(value float64) int {
+	if value <= t.LowerLimit || t.Width <= 0 {
+		return 0
+	}
+	idx := int(math.Ceil((value - t.LowerLimit) / t.Width))
+	if idx >= t.Count {
+		return t.Count - 1
+	}
+	return idx
+}
+
+// upperLimit - get upper limit of bin, +Inf for the last bin
+func (t *Table) upperLimit(idx int) float64 {
+	if idx == t.Count-1 {
+		return math.Inf(1)
+	}
+	return t.LowerLimit + float64(idx)*t.Width
+}
+
+// Tabulate - record value with weight, weight is a number of entries
+func (t *Table) Tabulate(value float64, weight int) {
+	t.frequencies[t.bin(value)] += float64(weight)
+	for i := 0; i < weight; i++ {
+		t.values.add(value)
+	}
+}
+
+// untabulate - remove value recorded with weight, it undoes Tabulate
+func (t *Table) untabulate(value float64, weight int) {
+	t.frequencies[t.bin(value)] -= float64(weight)
+	for i := 0; i < weight; i++ {
+		t.values.remove(value)
+	}
+}
+
+// Entries - get number of recorded values
+func (t *Table) Entries() int {
+	return len(t.values.values)
+}
+
+// Mean - get mean of recorded values
+func (t *Table) Mean() float64 {
+	return t.values.mean()
+}
+
+// StdDev - get standard deviation of recorded values
+func (t *Table) StdDev() float64 {
+	return t.values.stdDev()
+}
+
+// Percentile - get percentile of recorded values, p is from 0 to 100
+func (t *Table) Percentile(p float64) float64 {
+	return t.values.percentile(p)
+}
+
+// Frequencies - get frequencies of bins
+func (t *Table) Frequencies() []float64 {
+	return append([]float64(nil), t.frequencies...)
+}
+
+// Report - print report about table
+func (t *Table) Report() {
+	fmt.Println("Table name \"", t.Name, "\"")
+	fmt.Printf("Entries %d\tMean %.2f\tStd dev %.2f\n", t.Entries(), t.Mean(), t.StdDev())
+	fmt.Println("Upper limit\tFrequency\tPercent of total\tCumulative percent")
+	var cumulative float64
+	for i, f := range t.frequencies {
+		if f == 0 {
+			continue
+		}
+		cumulative += f
+		fmt.Printf("%.2f\t%2.f\t%.2f%%\t%.2f%%\n", t.upperLimit(i), f,
+			100*ratio(f, float64(t.Entries())), 100*ratio(cumulative, float64(t.Entries())))
+	}
+	fmt.Println()
+}
+
+// Stats - get statistics of table, frequencies of bins are named by upper
+// limit of bin, for example "bin_10" or "bin_inf" for the last bin
+func (t *Table) Stats() map[string]float64 {
+	stats := map[string]float64{
+		"entries": float64(t.Entries()),
+		"mean":    t.Mean(),
+	}
+	for i, f := range t.frequencies {
+		name := "bin_inf"
+		if i < t.Count-1 {
+			name = "bin_" + strconv.FormatFloat(t.upperLimit(i), 'g', -1, 64)
+		}
+		stats[name] = f
+	}
+	return statsSamples(stats, &t.values, "value")
+}
+
+// AddTable - register tables in pipeline
+func (p *Pipeline) AddTable(tables ...*Table) *Pipeline {
+	for _, t := range tables {
+		p.tables[t.Name] = t
+	}
+	return p
+}
+
+// GetTable - get table by name, nil if table not found
+func (p *Pipeline) GetTable(name string) *Table {
+	return p.tables[name]
+}
+
+// sortedTables - get tables ordered by name
+func (p *Pipeline) sortedTables() []*Table {
+	tables := make([]*Table, 0, len(p.tables))
+	for _, t := range p.tables {
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables
+}
+
+// Tabulate block records value of transact in Table
+type Tabulate struct {
+	BaseObj
+	table    *Table
+	Argument TableArgFunc // Tabulated value, Argument of table is used if nil
+	Weight   int          // Number of entries for each transact, 1 by default
+}
+
+// NewTabulate creates new Tabulate.
+// name - name of object; table - table for values; argument - tabulated
+// value, if nil Argument of table is used
+func NewTabulate(name string, table *Table, argument TableArgFunc) *Tabulate {
+	obj := &Tabulate{table: table, Argument: argument, Weight: 1}
+	obj.BaseObj.Init(name)
+	return obj
+}
+
+// SetPipeline - set pipeline of Tabulate, table is added to pipeline if it is
+// not added yet
+func (obj *Tabulate) SetPipeline(pipe *Pipeline) {
+	obj.BaseObj.SetPipeline(pipe)
+	if pipe.GetTable(obj.table.Name) == nil {
+		pipe.AddTable(obj.table)
+	}
+}
+
+// AppendTransact append transact to object, value is recorded before transact
+// goes to destination, so destination can not change it. Record is removed
+// if destination refuses transact.
+func (obj *Tabulate) AppendTransact(transact *Transaction) bool {
+	obj.BaseObj.AppendTransact(transact)
+	transact.SetHolder(obj.name)
+	argument := obj.Argument
+	if argument == nil {
+		argument = obj.table.Argument
+	}
+	var value float64
+	if argument != nil {
+		value = argument(transact)
+		obj.table.Tabulate(value, obj.Weight)
+	}
+	for _, v := range obj.GetDst() {
+		if v.AppendTransact(transact) {
+			return true
+		}
+	}
+	if argument != nil {
+		obj.table.untabulate(value, obj.Weight)
+	}
+	return false
+}
+
+// Report - print report about object
+func (obj *Tabulate) Report() {}
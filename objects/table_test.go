@@ -0,0 +1,97 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTable_Tabulate(t *testing.T) {
+	table := NewTable("table", nil, 10, 5, 4)
+	for _, v := range []float64{3, 10, 11, 15, 16, 40} {
+		table.Tabulate(v, 1)
+	}
+	table.Tabulate(12, 2)
+	// Bins: <= 10, (10, 15], (15, 20], > 20
+	expected := []float64{2, 4, 1, 1}
+	for i, f := range table.Frequencies() {
+		if f != expected[i] {
+			t.Error("Frequency of bin", i, "expected", expected[i], "got", f)
+		}
+	}
+	if table.Entries() != 8 || table.Mean() != 14.875 {
+		t.Error("Entries and mean, expected", 8, 15, "got", table.Entries(), table.Mean())
+	}
+	stats := table.Stats()
+	if stats["bin_10"] != 2 || stats["bin_20"] != 1 || stats["bin_inf"] != 1 {
+		t.Error("Bins in statistics, expected", expected, "got", stats)
+	}
+}
+
+func TestTabulate(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 5, nil)
+	advance := NewAdvance("advance", 3, 0)
+	transit := NewTable("transit", TransitTime, 0, 1, 10)
+	sizes := NewTable("sizes", nil, 0, 1, 5)
+	size := NewTabulate("size", sizes, func(transact *Transaction) float64 { return 2 })
+	size.Weight = 3
+	hole := NewHole("hole")
+	pipe.Append(gen, advance)
+	tabulate := NewTabulate("transit", transit, nil)
+	pipe.Append(advance, tabulate)
+	pipe.Append(tabulate, size)
+	pipe.Append(size, hole)
+	pipe.Append(hole)
+	res, err := pipe.Run(context.Background(), 100)
+	if err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if pipe.GetTable("transit") != transit || res.Table("transit") == nil {
+		t.Fatal("Table is not registered in pipeline")
+	}
+	if stats := res.Table("transit").Stats; stats["entries"] != 5 || stats["mean"] != 3 || stats["bin_3"] != 5 {
+		t.Error("Transit time, expected", 5, "entries in bin 3, got", stats)
+	}
+	if f := res.Table("sizes").Frequencies; f[2] != 15 {
+		t.Error("Weighted frequency, expected", 15, "got", f[2])
+	}
+}
+
+func TestTabulate_LiveTransit(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewGenerator("gen", 10, 0, 0, 0, nil)
+	before, after := NewAdvance("before", 3, 0), NewAdvance("after", 5, 0)
+	transit := NewTable("transit", TransitTime, 0, 1, 10)
+	tabulate := NewTabulate("transit", transit, nil)
+	hole := NewHole("hole")
+	pipe.Append(gen, before)
+	pipe.Append(before, tabulate)
+	pipe.Append(tabulate, after)
+	pipe.Append(after, hole)
+	pipe.Append(hole)
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if transit.Entries() == 0 || transit.Mean() != 3 {
+		t.Error("Transit time of live transacts, expected", 3, "got", transit.Mean())
+	}
+}
+
+func TestTabulate_Refused(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	table := NewTable("table", nil, 0, 1, 5)
+	tabulate := NewTabulate("tabulate", table, func(transact *Transaction) float64 { return 2 })
+	facility := NewFacility("facility", 10, 0)
+	pipe.Append(tabulate, facility)
+	pipe.Append(facility)
+	if !tabulate.AppendTransact(NewTransaction(pipe)) || tabulate.AppendTransact(NewTransaction(pipe)) {
+		t.Fatal("Facility must accept the first transact and refuse the second")
+	}
+	if table.Entries() != 1 || table.Frequencies()[2] != 1 {
+		t.Error("Entries of refused transact must be removed, expected", 1, "got", table.Entries())
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TraceRecord is a recorded arrival of trace, for example from production
+// logs. Trace-driven Generator replays arrivals at recorded times with
+// recorded parameters instead of sampling them from distribution.
+type TraceRecord struct {
+	Time       int         // Model time of arrival
+	Parameters []Parameter // Parameters of transaction
+}
+
+// TraceOptions is options of reading of trace
+type TraceOptions struct {
+	TimeField string  // Name of column or field with time of arrival, "time" by default
+	Scale     float64 // Multiplier of recorded time to model time, 1 by default. RFC3339 timestamps are in seconds.
+}
+
+// rawRecord is a record of trace before conversion of time
+type rawRecord struct {
+	line       int
+	time       float64
+	timestamp  time.Time
+	parameters []Parameter
+}
+
+// ReadTraceCSV reads trace from CSV with header, one of columns is time of
+// arrival, another columns are parameters of transaction
+func ReadTraceCSV(r io.Reader, opts TraceOptions) ([]TraceRecord, error) {
+	opts = opts.withDefaults()
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("trace: %v", err)
+	}
+	timeIdx := -1
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == opts.TimeField {
+			timeIdx = i
+		} else if err := checkParameterName(header[i]); err != nil {
+			return nil, fmt.Errorf("trace: column: %w", err)
+		}
+	}
+	if timeIdx < 0 {
+		return nil, fmt.Errorf("trace: column %q not found", opts.TimeField)
+	}
+	var raw []rawRecord
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("trace: %v", err)
+		}
+		rec := rawRecord{line: line}
+		for i, value := range record {
+			if i != timeIdx {
+				rec.parameters = append(rec.parameters, Parameter{Name: header[i], Value: parseTraceValue(value)})
+			}
+		}
+		if err := rec.parseTime(parseTraceValue(record[timeIdx])); err != nil {
+			return nil, err
+		}
+		raw = append(raw, rec)
+	}
+	return convertTrace(raw, opts)
+}
+
+// ReadTraceJSONL reads trace from JSON Lines, each line is an object with
+// time of arrival and parameters of transaction. Empty lines are skipped.
+func ReadTraceJSONL(r io.Reader, opts TraceOptions) ([]TraceRecord, error) {
+	opts = opts.withDefaults()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var raw []rawRecord
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var fields map[string]interface{}
+		if err := decoder.Decode(&fields); err != nil {
+			return nil, fmt.Errorf("trace: line %d: %v", line, err)
+		}
+		rec := rawRecord{line: line}
+		value, ok := fields[opts.TimeField]
+		if !ok {
+			return nil, fmt.Errorf("trace: line %d: field %q not found", line, opts.TimeField)
+		}
+		if err := rec.parseTime(convertJSONValue(value)); err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			if name == opts.TimeField {
+				continue
+			}
+			if err := checkParameterName(name); err != nil {
+				return nil, fmt.Errorf("trace: line %d: field: %w", line, err)
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rec.parameters = append(rec.parameters, Parameter{Name: name, Value: convertJSONValue(fields[name])})
+		}
+		raw = append(raw, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("trace: %v", err)
+	}
+	return convertTrace(raw, opts)
+}
+
+// withDefaults - set default values of options
+func (opts TraceOptions) withDefaults() TraceOptions {
+	if opts.TimeField == "" {
+		opts.TimeField = "time"
+	}
+	if opts.Scale == 0 {
+		opts.Scale = 1
+	}
+	return opts
+}
+
+// parseTime - set time of record from number or RFC3339 timestamp
+func (rec *rawRecord) parseTime(value interface{}) error {
+	switch v := value.(type) {
+	case int:
+		rec.time = float64(v)
+		return nil
+	case float64:
+		rec.time = v
+		return nil
+	case string:
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fmt.Errorf("trace: line %d: invalid time %q", rec.line, v)
+		}
+		rec.timestamp = ts
+		return nil
+	}
+	return fmt.Errorf("trace: line %d: invalid time %v", rec.line, value)
+}
+
+// convertTrace - convert recorded times to model time and sort records by time.
+// Timestamps are converted to seconds from the earliest timestamp.
+func convertTrace(raw []rawRecord, opts TraceOptions) ([]TraceRecord, error) {
+	var first time.Time
+	for i, rec := range raw {
+		if rec.timestamp.IsZero() != raw[0].timestamp.IsZero() {
+			return nil, fmt.Errorf("trace: line %d: numeric times and timestamps are mixed", rec.line)
+		}
+		if !rec.timestamp.IsZero() && (i == 0 || rec.timestamp.Before(first)) {
+			first = rec.timestamp
+		}
+	}
+	trace := make([]TraceRecord, len(raw))
+	for i, rec := range raw {
+		t := rec.time
+		if !rec.timestamp.IsZero() {
+			t = rec.timestamp.Sub(first).Seconds()
+		}
+		trace[i] = TraceRecord{Time: int(math.Round(t * opts.Scale)), Parameters: rec.parameters}
+	}
+	sort.SliceStable(trace, func(i, j int) bool { return trace[i].Time < trace[j].Time })
+	return trace, nil
+}
+
+// parseTraceValue - convert value of CSV to int, float64 or string
+func parseTraceValue(value string) interface{} {
+	value = strings.TrimSpace(value)
+	if v, err := strconv.Atoi(value); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v
+	}
+	return value
+}
+
+// convertJSONValue - convert number of JSON to int or float64
+func convertJSONValue(value interface{}) interface{} {
+	if n, ok := value.(json.Number); ok {
+		return parseTraceValue(n.String())
+	}
+	return value
+}
+
+// NewTraceGenerator creates new Generator, which replays arrivals of trace.
+// name - name of object; trace - recorded arrivals ordered by time
+func NewTraceGenerator(name string, trace []TraceRecord) *Generator {
+	obj := NewGenerator(name, 0, 0, 0, 0, nil)
+	obj.Trace = trace
+	return obj
+}
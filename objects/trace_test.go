@@ -0,0 +1,99 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadTraceCSV(t *testing.T) {
+	trace, err := ReadTraceCSV(strings.NewReader("class, time, size\nB, 120, 2.5\nA, 60, 3\n"),
+		TraceOptions{Scale: 1.0 / 60})
+	if err != nil {
+		t.Fatal("Read error, expected", nil, "got", err)
+	}
+	if len(trace) != 2 || trace[0].Time != 1 || trace[1].Time != 2 {
+		t.Fatal("Trace times, expected", []int{1, 2}, "got", trace)
+	}
+	expected := []Parameter{{Name: "class", Value: "A"}, {Name: "size", Value: 3}}
+	for i, p := range trace[0].Parameters {
+		if p != expected[i] {
+			t.Error("Parameter, expected", expected[i], "got", p)
+		}
+	}
+	if _, err := ReadTraceCSV(strings.NewReader("at,size\n1,2\n"), TraceOptions{}); err == nil {
+		t.Error("Read error without time column, expected error, got", err)
+	}
+}
+
+func TestReadTraceJSONL(t *testing.T) {
+	trace, err := ReadTraceJSONL(strings.NewReader(
+		`{"ts": "2019-05-01T10:02:00Z", "class": "B"}`+"\n\n"+
+			`{"ts": "2019-05-01T10:00:00Z", "class": "A", "size": 1.5}`),
+		TraceOptions{TimeField: "ts", Scale: 1.0 / 60})
+	if err != nil {
+		t.Fatal("Read error, expected", nil, "got", err)
+	}
+	if len(trace) != 2 || trace[0].Time != 0 || trace[1].Time != 2 {
+		t.Fatal("Trace times, expected", []int{0, 2}, "got", trace)
+	}
+	if p := trace[0].Parameters; len(p) != 2 || p[1].Value != 1.5 {
+		t.Error("Parameters, expected size", 1.5, "got", p)
+	}
+	if _, err := ReadTraceJSONL(strings.NewReader(`{"time": 1}`+"\n"+`{"time": `), TraceOptions{}); err == nil {
+		t.Error("Read error for broken line, expected error, got", err)
+	}
+}
+
+func TestGenerator_Trace(t *testing.T) {
+	trace, err := ReadTraceCSV(strings.NewReader("time,size\n5,1\n5,2\n12,3\n"), TraceOptions{})
+	if err != nil {
+		t.Fatal("Read error, expected", nil, "got", err)
+	}
+	pipe := NewPipelineWithSeed("pipe", 1)
+	sizes := 0
+	check := NewCheck("check", func(obj *Check, transact *Transaction) bool {
+		sizes += transact.GetIntParameter("size") * obj.Pipe.ModelTime
+		return true
+	}, nil)
+	gen := NewTraceGenerator("gen", trace)
+	pipe.AddObject(gen).AddObject(check).AddObject(NewHole("hole"))
+	if _, err := pipe.Run(context.Background(), 100); err != nil {
+		t.Fatal("Run error, expected", nil, "got", err)
+	}
+	if gen.id-1 != 3 {
+		t.Error("Generated, expected", 3, "got", gen.id-1)
+	}
+	if sizes != 1*5+2*5+3*12 {
+		t.Error("Sum of size by time, expected", 1*5+2*5+3*12, "got", sizes)
+	}
+}
+
+func TestTrace_ReservedParameters(t *testing.T) {
+	if _, err := ReadTraceCSV(strings.NewReader("time,id\n1,7\n"), TraceOptions{}); !errors.Is(err, ErrReservedParameter) {
+		t.Error("Read CSV with id column, expected", ErrReservedParameter, "got", err)
+	}
+	if _, err := ReadTraceJSONL(strings.NewReader(`{"time": 1, "born": 0}`), TraceOptions{}); !errors.Is(err, ErrReservedParameter) {
+		t.Error("Read JSONL with born field, expected", ErrReservedParameter, "got", err)
+	}
+	// Column with reserved name may be time of arrival
+	if _, err := ReadTraceCSV(strings.NewReader("born,size\n1,7\n"), TraceOptions{TimeField: "born"}); err != nil {
+		t.Error("Read CSV with born as time, expected", nil, "got", err)
+	}
+	pipe := NewPipelineWithSeed("pipe", 1)
+	gen := NewTraceGenerator("gen", []TraceRecord{{Time: 1, Parameters: []Parameter{{Name: "holder", Value: "x"}}}})
+	hole := NewHole("hole")
+	pipe.Append(gen, hole)
+	pipe.Append(hole)
+	if _, err := pipe.Run(context.Background(), 10); !errors.Is(err, ErrReservedParameter) {
+		t.Error("Run error, expected", ErrReservedParameter, "got", err)
+	}
+	if hole.cntTransact != 0 {
+		t.Error("Killed transacts, expected", 0, "got", hole.cntTransact)
+	}
+}
@@ -38,17 +38,29 @@ func NewTransactTable() *TransactTable {
 func (obj *TransactTable) Remove(transact *Transaction) {
 	defer obj.mu.Unlock()
 	obj.mu.Lock()
-	item := obj.mp[transact.GetID()]
+	obj.remove(transact.GetID())
+}
+
+// remove item from table and link its neighbors
+func (obj *TransactTable) remove(id int) *TableItem {
+	item := obj.mp[id]
 	if item == nil {
-		return
+		return nil
+	}
+	if prevoiseItem := obj.mp[item.prevoiseID]; prevoiseItem != nil {
+		prevoiseItem.nextID = item.nextID
+	}
+	if nextItem := obj.mp[item.nextID]; nextItem != nil {
+		nextItem.prevoiseID = item.prevoiseID
 	}
-	if item.prevoiseID != -1 {
-		prevoiseItem := obj.mp[item.prevoiseID]
-		if prevoiseItem != nil {
-			prevoiseItem.nextID = item.nextID
-		}
+	if obj.firstID == id {
+		obj.firstID = item.nextID
+	}
+	if obj.lastID == id {
+		obj.lastID = item.prevoiseID
 	}
-	delete(obj.mp, transact.GetID())
+	delete(obj.mp, id)
+	return item
 }
 
 // Items get all items of table
@@ -62,37 +74,73 @@ func (obj *TransactTable) Items() map[int]*TableItem {
 	return items
 }
 
+// List get all items of table in order of arrival (FIFO)
+func (obj *TransactTable) List() []*TableItem {
+	defer obj.mu.Unlock()
+	obj.mu.Lock()
+	items := make([]*TableItem, 0, len(obj.mp))
+	for item := obj.mp[obj.firstID]; item != nil; item = obj.mp[item.nextID] {
+		items = append(items, item)
+	}
+	return items
+}
+
 // Push transact to end table
 func (obj *TransactTable) Push(transact *Transaction) {
 	defer obj.mu.Unlock()
 	obj.mu.Lock()
-	if obj.firstID == -1 {
-		obj.firstID = transact.GetID()
+	obj.insertAfter(obj.lastID, transact)
+}
+
+// PushByPriority push transact to table after all transacts with greater or
+// equal priority, so table is ordered by priority and FIFO within a priority
+func (obj *TransactTable) PushByPriority(transact *Transaction) {
+	defer obj.mu.Unlock()
+	obj.mu.Lock()
+	prevoiseID := obj.lastID
+	for prevoiseID != -1 && obj.mp[prevoiseID].transact.GetPriority() < transact.GetPriority() {
+		prevoiseID = obj.mp[prevoiseID].prevoiseID
+	}
+	obj.insertAfter(prevoiseID, transact)
+}
+
+// insertAfter - insert transact after item with prevoiseID, -1 means insert
+// to begin of table
+func (obj *TransactTable) insertAfter(prevoiseID int, transact *Transaction) {
+	id := transact.GetID()
+	item := &TableItem{transact: transact, prevoiseID: prevoiseID, nextID: obj.firstID}
+	if prevoiseItem := obj.mp[prevoiseID]; prevoiseItem != nil {
+		item.nextID = prevoiseItem.nextID
+		prevoiseItem.nextID = id
 	} else {
-		lastTransact := obj.mp[obj.lastID]
-		if lastTransact != nil {
-			lastTransact.nextID = transact.GetID()
-		}
+		item.prevoiseID = -1
+		obj.firstID = id
 	}
-	obj.mp[transact.GetID()] = &TableItem{transact: transact, nextID: -1, prevoiseID: obj.lastID}
-	obj.lastID = transact.GetID()
+	if nextItem := obj.mp[item.nextID]; nextItem != nil {
+		nextItem.prevoiseID = id
+	} else {
+		item.nextID = -1
+		obj.lastID = id
+	}
+	obj.mp[id] = item
 }
 
-// Pop - return first transact from table and remove it from table
+// Pop - return first transact from table and remove it from table, nil if
+// table is empty
 func (obj *TransactTable) Pop() *Transaction {
 	defer obj.mu.Unlock()
 	obj.mu.Lock()
-	item := obj.mp[obj.firstID]
-	if item != nil {
-		r := obj.firstID
-		obj.firstID = item.nextID
-		delete(obj.mp, r)
+	item := obj.remove(obj.firstID)
+	if item == nil {
+		return nil
 	}
 	return item.transact
 }
 
 // Len - return length table
 func (obj *TransactTable) Len() int {
+	defer obj.mu.Unlock()
+	obj.mu.Lock()
 	return len(obj.mp)
 }
 
@@ -109,3 +157,10 @@ func (obj *TransactTable) First() *TableItem {
 	obj.mu.Lock()
 	return obj.mp[obj.firstID]
 }
+
+// Last - get last item in table
+func (obj *TransactTable) Last() *TableItem {
+	defer obj.mu.Unlock()
+	obj.mu.Lock()
+	return obj.mp[obj.lastID]
+}
@@ -0,0 +1,35 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"testing"
+)
+
+func TestTransactTable_List(t *testing.T) {
+	pipe := NewPipeline("pipe")
+	tb := NewTransactTable()
+	transacts := make([]*Transaction, 5)
+	for i := range transacts {
+		transacts[i] = NewTransaction(pipe)
+		tb.Push(transacts[i])
+	}
+	tb.Remove(transacts[0])
+	tb.Remove(transacts[2])
+	tb.Remove(transacts[4])
+	if tr := tb.Pop(); tr != transacts[1] {
+		t.Error("Pop transact, expected", transacts[1].GetID(), "got", tr.GetID())
+	}
+	tb.Push(transacts[0])
+	items := tb.List()
+	if len(items) != 2 || items[0].transact != transacts[3] || items[1].transact != transacts[0] {
+		t.Error("List of transacts, expected", []int{4, 1}, "got", items)
+	}
+	tb.Pop()
+	tb.Pop()
+	if tr := tb.Pop(); tr != nil {
+		t.Error("Pop from empty table, expected", nil, "got", tr.GetID())
+	}
+}
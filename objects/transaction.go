@@ -18,6 +18,15 @@ type Parameter struct {
 type Transaction struct {
 	pipe       *Pipeline              // Pipeline
 	parameters map[string]interface{} // Parameters of transaction
+	priority   int                    // Priority, greater value is higher priority
+	decisions  map[string]decision    // Decisions of objects, which refused transaction, by name of object
+}
+
+// decision is a random choice of object about refused transaction, it is
+// kept while transaction retries from the same holder
+type decision struct {
+	holder string      // Holder of transaction when decision was made
+	value  interface{} // Decision
 }
 
 // NewTransaction create new transaction
@@ -55,6 +64,7 @@ func NewTransaction(pipe *Pipeline) *Transaction {
 func (t *Transaction) Copy() *Transaction {
 	copyTr := &Transaction{}
 	copyTr.pipe = t.pipe
+	copyTr.priority = t.priority
 	copyTr.parameters = make(map[string]interface{})
 	for key, value := range t.parameters {
 		copyTr.parameters[key] = value
@@ -72,11 +82,30 @@ func (t *Transaction) GetID() int {
 	return t.GetIntParameter("id")
 }
 
+// SetPriority set transact priority, greater value is higher priority
+func (t *Transaction) SetPriority(priority int) {
+	t.priority = priority
+}
+
+// GetPriority - get transact priority
+func (t *Transaction) GetPriority() int {
+	return t.priority
+}
+
 // GetLife get transact time of life, rip - born
 func (t *Transaction) GetLife() int {
 	return t.GetIntParameter("rip") - t.GetIntParameter("born")
 }
 
+// GetTransitTime - get time since transact was generated, for killed
+// transact it is time of life
+func (t *Transaction) GetTransitTime() int {
+	if t.IsKilled() || t.pipe == nil {
+		return t.GetLife()
+	}
+	return t.pipe.ModelTime - t.GetIntParameter("born")
+}
+
 // PrintInfo - print info about transact
 func (t *Transaction) PrintInfo() {
 	utils.Log.Trace.Println("Transaction ID:\t", t.GetID(),
@@ -95,8 +124,13 @@ func (t *Transaction) SetTiсks(interval int) {
 
 // InqQueueTime - increment time in queue
 func (t *Transaction) InqQueueTime() {
-	t.SetParameter("timequeue", t.GetQueueTime()+1)
-	t.SetParameter("advance", t.GetAdvanceTime()+1)
+	t.AddQueueTime(1)
+}
+
+// AddQueueTime - increases time in queue and advance value by interval
+func (t *Transaction) AddQueueTime(interval int) {
+	t.SetParameter("timequeue", t.GetQueueTime()+interval)
+	t.SetParameter("advance", t.GetAdvanceTime()+interval)
 }
 
 // GetTicks - get current value of ticks
@@ -109,6 +143,29 @@ func (t *Transaction) IsTheEnd() bool {
 	return bool(t.GetIntParameter("ticks") == 0)
 }
 
+// getDecision - get decision of object about transact, decision made while
+// transact had another holder is not valid
+func (t *Transaction) getDecision(obj string) (interface{}, bool) {
+	d, ok := t.decisions[obj]
+	if !ok || d.holder != t.GetHolder() {
+		return nil, false
+	}
+	return d.value, true
+}
+
+// setDecision - keep decision of object about refused transact
+func (t *Transaction) setDecision(obj string, value interface{}) {
+	if t.decisions == nil {
+		t.decisions = make(map[string]decision)
+	}
+	t.decisions[obj] = decision{holder: t.GetHolder(), value: value}
+}
+
+// clearDecision - remove decision of object about transact
+func (t *Transaction) clearDecision(obj string) {
+	delete(t.decisions, obj)
+}
+
 // SetHolder - set holder of transact
 func (t *Transaction) SetHolder(holderName string) {
 	t.SetParameter("holder", holderName)
@@ -129,6 +186,11 @@ func (t *Transaction) DecTiсks() {
 	t.SetParameter("ticks", ticks)
 }
 
+// ResetTicks - set ticks value to zero, transact is ready to change state
+func (t *Transaction) ResetTicks() {
+	t.SetParameter("ticks", 0)
+}
+
 // Kill transact
 func (t *Transaction) Kill() {
 	t.SetParameter("rip", t.pipe.ModelTime)
@@ -175,14 +237,21 @@ func (t *Transaction) SetParts(part, parts, parentID int) {
 	})
 }
 
-// SetParameters - set parameters to transuct
+// SetParameters - set parameters to transuct. Values of parameters declared
+// in pipeline are converted to declared type, parameter with invalid value is
+// not set and simulation is stopped with model error.
 func (t *Transaction) SetParameters(parameters []Parameter) {
 	for _, v := range parameters {
-		if v.Value != nil {
-			t.parameters[v.Name] = v.Value
-		} else {
+		if v.Value == nil {
 			delete(t.parameters, v.Name)
+			continue
+		}
+		value, err := t.checkParameter(v.Name, v.Value)
+		if err != nil {
+			t.pipe.Fail(err)
+			continue
 		}
+		t.parameters[v.Name] = value
 	}
 }
 
@@ -191,8 +260,13 @@ func (t *Transaction) GetParameters() map[string]interface{} {
 	return t.parameters
 }
 
-// SetParameter - set value of parameter
+// SetParameter - set value of parameter, value is checked as in SetParameters
 func (t Transaction) SetParameter(name string, value interface{}) {
+	value, err := t.checkParameter(name, value)
+	if err != nil {
+		t.pipe.Fail(err)
+		return
+	}
 	t.parameters[name] = value
 }
 
@@ -201,12 +275,14 @@ func (t *Transaction) GetParameter(name string) interface{} {
 	return t.parameters[name]
 }
 
-// GetIntParameter - get int parameter of transact by name
+// GetIntParameter - get int parameter of transact by name. Missing or not
+// int parameter stops simulation with model error, 0 is returned.
 func (t *Transaction) GetIntParameter(name string) int {
-	return t.GetParameter(name).(int)
+	return Require[int](t, name)
 }
 
-// GetStringParameter - get string parameter of transact by name
+// GetStringParameter - get string parameter of transact by name. Missing or
+// not string parameter stops simulation with model error, "" is returned.
 func (t *Transaction) GetStringParameter(name string) string {
-	return t.GetParameter(name).(string)
+	return Require[string](t, name)
 }
@@ -0,0 +1,152 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"fmt"
+)
+
+// TransferMode defines how Transfer selects destination of transaction
+type TransferMode int
+
+const (
+	// TransferFractional - transact goes to the first destination with
+	// probability, otherwise to the second destination
+	TransferFractional TransferMode = iota
+	// TransferBoth - transact tries the first destination, then the second
+	TransferBoth
+	// TransferAll - transact tries all destinations in order
+	TransferAll
+	// TransferPick - destination is selected uniformly at random
+	TransferPick
+	// TransferConditional - destination is selected by function
+	TransferConditional
+)
+
+// HandleTransferFunc is a function signature for conditional transfer, it
+// returns index of destination, negative index if transact is refused
+type HandleTransferFunc func(obj *Transfer, transact *Transaction) int
+
+// Transfer routes the Active Transaction to one of destinations
+type Transfer struct {
+	BaseObj
+	// Mode of transfer
+	Mode TransferMode
+	// Probability of the first destination for TransferFractional
+	Probability float64
+	// Number of random stream, RN1 by default
+	Stream int
+	// Function for selecting destination for TransferConditional
+	HandleTransfer HandleTransferFunc
+	// For counting the transacts that go to each destination
+	cntBranch map[int]float64
+	// For counting the transacts that go through transfer
+	cntTransact float64
+}
+
+// NewTransfer creates new Transfer.
+// name - name of object; mode - mode of transfer; probability - probability of
+// the first destination for TransferFractional; hndl - function for selecting
+// destination for TransferConditional
+func NewTransfer(name string, mode TransferMode, probability float64, hndl HandleTransferFunc) *Transfer {
+	obj := &Transfer{Mode: mode, Probability: probability, HandleTransfer: hndl}
+	obj.name = name
+	obj.cntBranch = make(map[int]float64)
+	return obj
+}
+
+// candidates - get indexes of destinations for transact in order of trying
+func (obj *Transfer) candidates(transact *Transaction) []int {
+	dst := obj.GetDst()
+	if len(dst) == 0 {
+		return nil
+	}
+	switch obj.Mode {
+	case TransferFractional, TransferPick:
+		// Refused transact retries the same destination from the same holder
+		if idx, ok := transact.getDecision(obj.name); ok {
+			return []int{idx.(int)}
+		}
+		return []int{obj.choose(len(dst))}
+	case TransferBoth:
+		if len(dst) == 1 {
+			return []int{0}
+		}
+		return []int{0, 1}
+	case TransferConditional:
+		if obj.HandleTransfer == nil {
+			return nil
+		}
+		idx := obj.HandleTransfer(obj, transact)
+		if idx < 0 || idx >= len(dst) {
+			return nil
+		}
+		return []int{idx}
+	}
+	all := make([]int, len(dst))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// choose - select destination at random for TransferFractional and
+// TransferPick
+func (obj *Transfer) choose(cnt int) int {
+	if obj.Mode == TransferPick {
+		return obj.Pipe.RN(obj.Stream).Intn(cnt)
+	}
+	if cnt == 1 || obj.Pipe.RN(obj.Stream).Float64() < obj.Probability {
+		return 0
+	}
+	return 1
+}
+
+// AppendTransact append transact to object, transact goes to destination
+// selected by mode of transfer. Returns false if destination refuses transact.
+// Destination selected at random is kept for refused transact, so blocked
+// transacts do not drift to another destination.
+func (obj *Transfer) AppendTransact(transact *Transaction) bool {
+	obj.BaseObj.AppendTransact(transact)
+	dst := obj.GetDst()
+	candidates := obj.candidates(transact)
+	for _, idx := range candidates {
+		if dst[idx].AppendTransact(transact) {
+			transact.clearDecision(obj.name)
+			obj.cntBranch[idx]++
+			obj.cntTransact++
+			return true
+		}
+	}
+	if obj.Mode == TransferFractional || obj.Mode == TransferPick {
+		if len(candidates) > 0 {
+			transact.setDecision(obj.name, candidates[0])
+		}
+	}
+	return false
+}
+
+// Report - print report about object
+func (obj *Transfer) Report() {
+	obj.BaseObj.Report()
+	fmt.Printf("Number entries %.2f\n", obj.cntTransact)
+	for i, v := range obj.GetDst() {
+		fmt.Printf("To \"%s\" %.2f\t%.2f%%\n", v.GetName(), obj.cntBranch[i],
+			100*ratio(obj.cntBranch[i], obj.cntTransact))
+	}
+	fmt.Println()
+}
+
+// Stats - get statistics of object, number of transacts for each destination
+// is stored by key "to <name of destination>"
+func (obj *Transfer) Stats() map[string]float64 {
+	stats := map[string]float64{
+		"entries": obj.cntTransact,
+	}
+	for i, v := range obj.GetDst() {
+		stats["to "+v.GetName()] = obj.cntBranch[i]
+	}
+	return stats
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 Sergey Soldatov. All rights reserved.
+// This software may be modified and distributed under the terms
+// of the Apache license. See the LICENSE file for details.
+
+package objects
+
+import (
+	"testing"
+)
+
+func newTransferPipeline(transfer *Transfer, cnt int) (*Pipeline, []*Hole) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	holes := make([]*Hole, cnt)
+	dst := make([]IBaseObj, cnt)
+	for i := range holes {
+		holes[i] = NewHole("hole " + string(rune('A'+i)))
+		dst[i] = holes[i]
+	}
+	pipe.Append(transfer, dst...)
+	pipe.AppendMultiple(dst)
+	return pipe, holes
+}
+
+func TestTransfer_Fractional(t *testing.T) {
+	transfer := NewTransfer("transfer", TransferFractional, 0.3, nil)
+	pipe, holes := newTransferPipeline(transfer, 2)
+	for i := 0; i < 1000; i++ {
+		transfer.AppendTransact(NewTransaction(pipe))
+	}
+	if holes[0].cntTransact < 250 || holes[0].cntTransact > 350 {
+		t.Error("Transacts to first destination, expected about", 300, "got", holes[0].cntTransact)
+	}
+	stats := transfer.Stats()
+	if stats["entries"] != 1000 || stats["to hole A"]+stats["to hole B"] != 1000 {
+		t.Error("Transfer stats, expected", 1000, "got", stats)
+	}
+}
+
+func TestTransfer_Pick(t *testing.T) {
+	transfer := NewTransfer("transfer", TransferPick, 0, nil)
+	pipe, holes := newTransferPipeline(transfer, 3)
+	for i := 0; i < 900; i++ {
+		transfer.AppendTransact(NewTransaction(pipe))
+	}
+	for _, hole := range holes {
+		if hole.cntTransact < 250 || hole.cntTransact > 350 {
+			t.Error("Transacts to", hole.GetName(), "expected about", 300, "got", hole.cntTransact)
+		}
+	}
+}
+
+func TestTransfer_Both(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	facility := NewFacility("facility", 10, 0)
+	hole := NewHole("hole")
+	transfer := NewTransfer("transfer", TransferBoth, 0, nil)
+	pipe.Append(transfer, facility, hole)
+	pipe.Append(facility, hole)
+	pipe.Append(hole)
+	transfer.AppendTransact(NewTransaction(pipe))
+	transfer.AppendTransact(NewTransaction(pipe))
+	if facility.cntTransact != 1 || hole.cntTransact != 1 {
+		t.Error("Transacts to facility and hole, expected", 1, 1, "got", facility.cntTransact, hole.cntTransact)
+	}
+}
+
+func TestTransfer_Conditional(t *testing.T) {
+	transfer := NewTransfer("transfer", TransferConditional, 0,
+		func(obj *Transfer, transact *Transaction) int {
+			return transact.GetPriority() - 1
+		})
+	pipe, holes := newTransferPipeline(transfer, 2)
+	for _, priority := range []int{0, 1, 2, 2} {
+		transact := NewTransaction(pipe)
+		transact.SetPriority(priority)
+		if transfer.AppendTransact(transact) != (priority > 0) {
+			t.Error("Transfer of transact with priority", priority, "expected", priority > 0)
+		}
+	}
+	if holes[0].cntTransact != 1 || holes[1].cntTransact != 2 {
+		t.Error("Transacts to destinations, expected", 1, 2, "got", holes[0].cntTransact, holes[1].cntTransact)
+	}
+}
+
+func TestTransfer_FractionalBlocked(t *testing.T) {
+	pipe := NewPipelineWithSeed("pipe", 1)
+	facility := NewFacility("facility", 10, 0)
+	hole := NewHole("hole")
+	transfer := NewTransfer("transfer", TransferFractional, 0.5, nil)
+	pipe.Append(transfer, facility, hole)
+	pipe.Append(facility, hole)
+	pipe.Append(hole)
+	// Facility is busy, transacts selected for it are refused
+	facility.AppendTransact(NewTransaction(pipe))
+	transacts := make([]*Transaction, 100)
+	refused := 0
+	for i := range transacts {
+		transacts[i] = NewTransaction(pipe)
+		if !transfer.AppendTransact(transacts[i]) {
+			refused++
+		}
+	}
+	// Refused transacts retry and must keep the selected destination
+	kept := 0
+	for _, transact := range transacts {
+		if _, ok := transact.getDecision(transfer.name); !ok {
+			continue
+		}
+		kept++
+		for retry := 0; retry < 10; retry++ {
+			if transfer.AppendTransact(transact) {
+				t.Fatal("Refused transact", transact.GetID(), "went to another destination")
+			}
+		}
+	}
+	if refused < 35 || refused > 65 || kept != refused {
+		t.Error("Refused transacts, expected about", 50, "got", refused, kept)
+	}
+	// Transact routed away by another block does not keep destination
+	transacts[0].SetHolder("other")
+	if _, ok := transacts[0].getDecision(transfer.name); ok {
+		t.Error("Decision of moved transact, expected", false, "got", ok)
+	}
+	if hole.cntTransact != float64(100-refused) {
+		t.Error("Transacts to hole, expected", 100-refused, "got", hole.cntTransact)
+	}
+}